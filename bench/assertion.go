@@ -0,0 +1,187 @@
+package bench
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Assertion types define what is checked in a response besides its status
+// code.
+const (
+	// AssertBodyContains checks that the body contains a substring.
+	AssertBodyContains = "body-contains"
+	// AssertBodyRegex checks that the body matches a regular expression.
+	AssertBodyRegex = "body-regex"
+	// AssertJSON checks that the value of a path in a JSON body equals a
+	// value (i.e. data.status=ok or data.count=5).
+	AssertJSON = "json"
+	// AssertHeader checks that a response header exists (i.e. X-Version) or
+	// that it equals a value (i.e. Content-Type: application/json).
+	AssertHeader = "header"
+	// AssertBodySize checks that the length of the body is in a range of
+	// bytes (i.e. 100-2048, 100- or -2048).
+	AssertBodySize = "body-size"
+	// AssertMaxLatency checks that the response time does not exceed a
+	// duration (i.e. 200ms).
+	AssertMaxLatency = "max-latency"
+)
+
+// Assertion is a check of a response. A response failing an assertion is
+// counted as a failed response.
+type Assertion struct {
+	// Type of the assertion and its expression.
+	Type, Expression string
+
+	regexp           *regexp.Regexp
+	key, value       string
+	hasValue         bool
+	minSize, maxSize int
+	maxLatency       time.Duration
+}
+
+// NewAssertion creates an assertion of the given type.
+func NewAssertion(assertionType, expression string) (*Assertion, error) {
+	a := &Assertion{Type: assertionType, Expression: expression, maxSize: -1}
+
+	if expression == "" {
+		return nil, fmt.Errorf("Expression of %s assertion is empty", assertionType)
+	}
+
+	switch assertionType {
+	case AssertBodyContains:
+	case AssertBodyRegex:
+		re, err := regexp.Compile(expression)
+
+		if err != nil {
+			return nil, fmt.Errorf("Wrong regular expression: %v", err)
+		}
+
+		a.regexp = re
+	case AssertJSON:
+		index := strings.Index(expression, "=")
+
+		if index <= 0 {
+			return nil, fmt.Errorf("Wrong json assertion: %s (The format should be path=value)", expression)
+		}
+
+		a.key, a.value = expression[:index], expression[index+1:]
+	case AssertHeader:
+		a.key = strings.TrimSpace(expression)
+
+		if index := strings.Index(expression, ":"); index >= 0 {
+			a.key, a.value, a.hasValue = strings.TrimSpace(expression[:index]), strings.TrimSpace(expression[index+1:]), true
+		}
+
+		if a.key == "" {
+			return nil, fmt.Errorf("Wrong header assertion: %s", expression)
+		}
+	case AssertBodySize:
+		if err := a.parseBodySize(expression); err != nil {
+			return nil, err
+		}
+	case AssertMaxLatency:
+		latency, err := time.ParseDuration(expression)
+
+		if err != nil || latency <= 0 {
+			return nil, fmt.Errorf("Wrong max latency: %s", expression)
+		}
+
+		a.maxLatency = latency
+	default:
+		return nil, fmt.Errorf("Wrong assertion type: %s", assertionType)
+	}
+
+	return a, nil
+}
+
+// NewAssertionString creates an assertion using a string in the format of
+// type:expression (i.e. 'body-contains:"ok"', 'json:data.status=ok',
+// 'header:Content-Type: application/json', 'body-size:10-2048' or
+// 'max-latency:200ms').
+func NewAssertionString(assertion string) (*Assertion, error) {
+	index := strings.Index(assertion, ":")
+
+	if index <= 0 {
+		return nil, fmt.Errorf("Wrong assertion format: %s", assertion)
+	}
+
+	return NewAssertion(strings.TrimSpace(assertion[:index]), assertion[index+1:])
+}
+
+func (a *Assertion) parseBodySize(expression string) error {
+	bounds := strings.SplitN(expression, "-", 2)
+
+	if len(bounds) != 2 || bounds[0] == "" && bounds[1] == "" {
+		return fmt.Errorf("Wrong body size range: %s (The format should be min-max)", expression)
+	}
+
+	var err error
+
+	if bounds[0] != "" {
+		if a.minSize, err = strconv.Atoi(bounds[0]); err != nil || a.minSize < 0 {
+			return fmt.Errorf("Wrong body size range: %s", expression)
+		}
+	}
+
+	if bounds[1] != "" {
+		if a.maxSize, err = strconv.Atoi(bounds[1]); err != nil || a.maxSize < a.minSize {
+			return fmt.Errorf("Wrong body size range: %s", expression)
+		}
+	}
+
+	return nil
+}
+
+// String returns the name which the failures of the assertion are reported
+// under.
+func (a *Assertion) String() string {
+	return a.Type + ":" + a.Expression
+}
+
+// check returns an error describing why a response fails the assertion.
+func (a *Assertion) check(resp *response, responseTime time.Duration) error {
+	switch a.Type {
+	case AssertBodyContains:
+		if !strings.Contains(string(resp.body), a.Expression) {
+			return fmt.Errorf("Body does not contain %s", a.Expression)
+		}
+	case AssertBodyRegex:
+		if !a.regexp.Match(resp.body) {
+			return fmt.Errorf("Body does not match %s", a.Expression)
+		}
+	case AssertJSON:
+		value, err := jsonPathString(resp.body, a.key)
+
+		if err != nil {
+			return err
+		}
+
+		if value != a.value {
+			return fmt.Errorf("Value of %s is %s", a.key, value)
+		}
+	case AssertHeader:
+		values, ok := resp.header[http.CanonicalHeaderKey(a.key)]
+
+		if !ok || len(values) == 0 {
+			return fmt.Errorf("No header %s", a.key)
+		}
+
+		if a.hasValue && values[0] != a.value {
+			return fmt.Errorf("Header %s is %s", a.key, values[0])
+		}
+	case AssertBodySize:
+		if size := len(resp.body); size < a.minSize || a.maxSize >= 0 && size > a.maxSize {
+			return fmt.Errorf("Body size is %d bytes", size)
+		}
+	case AssertMaxLatency:
+		if responseTime > a.maxLatency {
+			return fmt.Errorf("Response time is %v", responseTime)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,98 @@
+package bench
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/sasanrose/gbench/report"
+)
+
+func TestAssertion(t *testing.T) {
+	resp := &response{
+		header: http.Header{"Content-Type": []string{"application/json"}},
+		body:   []byte(`{"status": "ok", "data": {"count": 5}}`),
+	}
+
+	tests := map[string]bool{
+		`body-contains:"ok"`:                    true,
+		`body-contains:error`:                   false,
+		`body-regex:"count":\s*\d+`:             true,
+		`body-regex:^<html>`:                    false,
+		`json:status=ok`:                        true,
+		`json:data.count=5`:                     true,
+		`json:data.count=6`:                     false,
+		`json:data.missing=5`:                   false,
+		`header:content-type`:                   true,
+		`header:Content-Type: application/json`: true,
+		`header:Content-Type: text/html`:        false,
+		`header:X-Version`:                      false,
+		`body-size:10-100`:                      true,
+		`body-size:-10`:                         false,
+		`body-size:100-`:                        false,
+		`max-latency:200ms`:                     true,
+		`max-latency:50ms`:                      false,
+	}
+
+	for assertion, expected := range tests {
+		a, err := NewAssertionString(assertion)
+
+		if err != nil {
+			t.Fatalf("Unexpected error for %s: %v", assertion, err)
+		}
+
+		if err := a.check(resp, 100*time.Millisecond); (err == nil) != expected {
+			t.Errorf("Expected %v for %s but got %v", expected, assertion, err)
+		}
+	}
+
+	wrongAssertions := map[string]string{
+		"body-contains":    "Wrong assertion format: body-contains",
+		"xpath:/a":         "Wrong assertion type: xpath",
+		"body-contains:":   "Expression of body-contains assertion is empty",
+		"body-regex:(":     "Wrong regular expression: error parsing regexp: missing closing ): `(`",
+		"json:status":      "Wrong json assertion: status (The format should be path=value)",
+		"header:: value":   "Wrong header assertion: : value",
+		"body-size:100":    "Wrong body size range: 100 (The format should be min-max)",
+		"body-size:100-10": "Wrong body size range: 100-10",
+		"max-latency:200":  "Wrong max latency: 200",
+	}
+
+	for assertion, expected := range wrongAssertions {
+		if _, err := NewAssertionString(assertion); err == nil || err.Error() != expected {
+			t.Errorf("Expected %q for %s but got %v", expected, assertion, err)
+		}
+	}
+}
+
+func TestExecAssertions(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status": "error"}`))
+	}))
+	defer ts.Close()
+
+	status, _ := WithAssertionString("json:status=ok")
+	size, _ := WithAssertionString("body-size:-100")
+	latency, _ := WithAssertionString("max-latency:1ns")
+
+	r := &report.Result{}
+	r.Init(1)
+
+	withURL, _ := WithURLSettings(ts.URL, "GET", []string{}, []string{}, "", "", status, size, latency)
+
+	if err := NewBench(WithRequests(3), withURL, WithReport(r)).Exec(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if r.FailedRequests != 3 || r.SuccessfulRequests != 0 || r.FailedResponseStatusCode[ts.URL][http.StatusOK] != 3 {
+		t.Errorf("Expected all the responses to fail but got %d successful and %d failed", r.SuccessfulRequests, r.FailedRequests)
+	}
+
+	failedAssertions := r.FailedAssertions[ts.URL]
+
+	if len(failedAssertions) != 2 || failedAssertions["json:status=ok"] != 3 || failedAssertions["max-latency:1ns"] != 3 {
+		t.Errorf("Unexpected failed assertions: %v", failedAssertions)
+	}
+}
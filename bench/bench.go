@@ -2,6 +2,8 @@
 package bench
 
 import (
+	"crypto/tls"
+	"fmt"
 	"io"
 	"net/http"
 	"sync"
@@ -10,13 +12,40 @@ import (
 	"github.com/sasanrose/gbench/report"
 )
 
+const (
+	defaultMaxInFlight  = 1000
+	defaultMaxRedirects = 10
+)
+
 // Bench represents a new benchmark that we want to execute.
 type Bench struct {
 	// Number of concurrent requests as well as total number of requests to
-	// send.
+	// send. When a duration or stages are set, zero requests means no request
+	// limit.
 	Concurrency, Requests int
+	// Optional duration of the benchmark. No new request is sent after the
+	// duration is elapsed.
+	Duration time.Duration
+	// Optional number of requests per second. When set, requests are sent at
+	// fixed intervals regardless of the in-flight requests (open model)
+	// instead of concurrent batches.
+	Rate float64
+	// Maximum number of in-flight requests when a rate is set. Requests which
+	// would exceed it are dropped.
+	MaxInFlight int
+	// Optional expected interval between requests of a worker. When set, the
+	// report also keeps response times corrected for coordinated omission.
+	ExpectedInterval time.Duration
+	// Optional load stages. When set, concurrency follows the stages instead
+	// of Concurrency and the benchmark stops after the last stage. Stages are
+	// not used when a rate is set.
+	Stages []*Stage
 	// Benchmarking endpoints.
 	URLs []*URL
+	// Optional scenarios. Each of the concurrent virtual users runs the steps
+	// of a scenario on a loop and the requests are the iterations of the
+	// scenario. Scenarios are not supported when a rate is set.
+	Scenarios []*Scenario
 	// Optional basic HTTP authentication.
 	Auth *Auth
 	// Optional proxy address to use (Does not support authentication).
@@ -31,10 +60,42 @@ type Bench struct {
 	OutputWriterLock *sync.Mutex
 	// Connection and response timeouts
 	ResponseTimeout, ConnectionTimeout time.Duration
+	// Optionally opens a new connection for every request instead of reusing
+	// idle ones.
+	DisableKeepAlives bool
+	// Optional maximum number of idle connections kept per host (Default is
+	// 2) and maximum number of connections per host (Default is no limit).
+	MaxIdleConnsPerHost, MaxConnsPerHost int
+	// Optional time after which idle connections are closed (Default is no
+	// limit).
+	IdleConnTimeout time.Duration
+	// Optional TLS configuration of the connections (i.e. a private CA or a
+	// client certificate).
+	TLSConfig *tls.Config
+	// Optionally reports redirect responses as they are instead of following
+	// them.
+	DisableRedirects bool
+	// Maximum number of redirects to follow (Default is 10). Requests which
+	// exceed it fail.
+	MaxRedirects int
+	// Optional protocol of the requests (Default is HTTP/1.1).
+	Protocol string
+	// Optional number of concurrent streams per HTTP/2 connection. When set,
+	// every group of that many workers shares a connection of its own.
+	StreamsPerConnection int
 	// Optional HTTP raw cookie string (i.e. the result of document.cookie).
 	RawCookie string
+	// Optional feeder of the values of {{feed column}} template expressions.
+	Feeder *Feeder
 	// Report to use
 	Report report.Report
+
+	// Parsed templates of the headers and the raw cookie and the error of
+	// parsing them which is returned by Exec.
+	templates    *requestTemplates
+	templatesErr error
+	// Sequence number of the last request with templates.
+	seq int64
 }
 
 // URL represents an endpoint that we want to benchmark.
@@ -43,12 +104,76 @@ type URL struct {
 	Addr, Method string
 	// Optional data to send in the format of key-value.
 	Data map[string]string
+	// Optional raw request body. It can not be used along with Data.
+	Body []byte
+	// Content type of the raw request body.
+	ContentType string
+	// Whether the raw request body is sent as is without evaluating its
+	// template expressions (i.e. bodies read from files).
+	RawBody bool
+	// Optional parts of a multipart/form-data request body. They can not be
+	// used along with Data or Body.
+	Parts []*Part
 	// Optional URL specific HTTP request headers.
 	Headers map[string]string
 	// Optional URL specific HTTP raw cookie string (i.e. the result of document.cookie).
 	RawCookie string
 	// Optional URL specific basic HTTP authentication.
 	Auth *Auth
+	// Optional checks of the responses besides their status codes.
+	Assertions []*Assertion
+
+	// Parsed templates which are evaluated for every request.
+	templates *requestTemplates
+}
+
+// Stage represents a step of a load profile. Concurrency changes linearly
+// from From to To during the stage.
+type Stage struct {
+	// Optional name of the stage (Default is "stage" followed by its
+	// position).
+	Name string
+	// Duration of the stage.
+	Duration time.Duration
+	// Concurrency at the beginning and the end of the stage. Set To to From
+	// to keep the concurrency during the stage.
+	From, To int
+}
+
+// Scenario represents an ordered list of requests sent by a virtual user.
+// Values extracted from the responses of a step can be used in the templates
+// of the next steps (i.e. {{var token}}).
+type Scenario struct {
+	// Optional name of the scenario (Default is "scenario" followed by its
+	// position).
+	Name string
+	// Steps of the scenario in order.
+	Steps []*Step
+}
+
+// Step represents a request of a scenario.
+type Step struct {
+	// Optional name of the step (Default is "step" followed by its position).
+	Name string
+	// Endpoint of the step.
+	URL *URL
+	// Optional values to extract from the response of the step.
+	Extractions []*Extraction
+}
+
+// Part represents a form field or a file of a multipart/form-data request
+// body.
+type Part struct {
+	// Name of the form field.
+	Name string
+	// Value of the form field. It is not used for file parts.
+	Value string
+	// Optional path of the file to upload. The file is streamed for every
+	// request instead of being kept in memory.
+	File string
+	// Optional file name and content type of a file part (Default is the base
+	// name of the file and application/octet-stream).
+	FileName, ContentType string
 }
 
 // Auth is used for a basic HTTP authentication.
@@ -57,6 +182,16 @@ type Auth struct {
 	Username, Password string
 }
 
+// getTLSConfig returns the TLS configuration and creates it when it is not
+// set yet.
+func (b *Bench) getTLSConfig() *tls.Config {
+	if b.TLSConfig == nil {
+		b.TLSConfig = &tls.Config{}
+	}
+
+	return b.TLSConfig
+}
+
 // NewBench creates a new benchmark given a list of configurations. A
 // config can be created on the fly or using the predefined functions.
 func NewBench(configurations ...func(*Bench)) *Bench {
@@ -82,11 +217,45 @@ func NewBench(configurations ...func(*Bench)) *Bench {
 		b.OutputWriterLock = &sync.Mutex{}
 	}
 
+	// WithHeader and WithRawCookie do not validate their values, so an
+	// invalid expression is reported when the benchmark is executed.
+	b.templates, b.templatesErr = newBenchTemplates(b)
+
 	if b.Concurrency == 0 {
 		b.Concurrency = 1
 	}
 
-	if b.Requests == 0 {
+	for i, stage := range b.Stages {
+		if stage.Name == "" {
+			stage.Name = fmt.Sprintf("stage %d", i+1)
+		}
+	}
+
+	for i, scenario := range b.Scenarios {
+		if scenario.Name == "" {
+			scenario.Name = fmt.Sprintf("scenario %d", i+1)
+		}
+
+		for j, step := range scenario.Steps {
+			if step.Name == "" {
+				step.Name = fmt.Sprintf("step %d", j+1)
+			}
+		}
+	}
+
+	if b.MaxRedirects == 0 {
+		b.MaxRedirects = defaultMaxRedirects
+	}
+
+	if b.Protocol == "" {
+		b.Protocol = ProtocolHTTP1
+	}
+
+	if b.Rate > 0 && b.MaxInFlight == 0 {
+		b.MaxInFlight = defaultMaxInFlight
+	}
+
+	if b.Requests == 0 && b.Duration == 0 && len(b.Stages) == 0 {
 		b.Requests = 1
 	}
 
@@ -1,12 +1,18 @@
 package bench
 
 import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"net/http"
 	"net/url"
+	"os"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
@@ -29,6 +35,73 @@ func WithRequests(n int) func(*Bench) {
 	}
 }
 
+// WithDuration creates a config to set the duration of the benchmark. If
+// number of requests is set as well, the benchmark stops as soon as one of
+// them is reached.
+func WithDuration(d time.Duration) func(*Bench) {
+	return func(b *Bench) {
+		b.Duration = d
+	}
+}
+
+// WithRate creates a config to send requests at a constant rate of requests
+// per second for each URL.
+func WithRate(rate float64) func(*Bench) {
+	return func(b *Bench) {
+		b.Rate = rate
+	}
+}
+
+// WithRateString creates a config to send requests at a constant rate using
+// a string in the format of number/unit (i.e. 500/s, 30/m or 10/100ms). A
+// number without unit means requests per second.
+func WithRateString(rate string) (func(*Bench), error) {
+	parsedRate, err := parseRate(rate)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return WithRate(parsedRate), nil
+}
+
+// WithMaxInFlight creates a config to set the maximum number of in-flight
+// requests when a rate is set.
+func WithMaxInFlight(n int) func(*Bench) {
+	return func(b *Bench) {
+		b.MaxInFlight = n
+	}
+}
+
+// WithExpectedInterval creates a config to set the expected interval between
+// requests of a worker which is used to correct the response times for
+// coordinated omission.
+func WithExpectedInterval(interval time.Duration) func(*Bench) {
+	return func(b *Bench) {
+		b.ExpectedInterval = interval
+	}
+}
+
+// WithStage adds a load stage.
+func WithStage(stage *Stage) func(*Bench) {
+	return func(b *Bench) {
+		b.Stages = append(b.Stages, stage)
+	}
+}
+
+// WithStageString adds a load stage using a string in the format of
+// [name=]duration:from[-to] (i.e. ramp=2m:10-200 or 5m:200). Without to,
+// concurrency is kept at from during the stage.
+func WithStageString(stage string) (func(*Bench), error) {
+	parsedStage, err := parseStage(stage)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return WithStage(parsedStage), nil
+}
+
 // WithURL adds an endpoint to benchmark.
 func WithURL(u *URL) func(*Bench) {
 	return func(b *Bench) {
@@ -82,14 +155,51 @@ func WithProxy(addr string) func(*Bench) {
 }
 
 // WithURLSettings sets a benchmarking endpoint using sepcific URL settings.
+// See NewURL for the settings.
 func WithURLSettings(requestedURL,
 	method string,
 	data []string,
 	headers []string,
 	rawCookie string,
 	userPass string,
+	urlConfigurations ...func(*URL),
 ) (func(*Bench), error) {
-	parsedURL, err := url.Parse(requestedURL)
+	endpoint, err := NewURL(requestedURL, method, data, headers, rawCookie, userPass, urlConfigurations...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return WithURL(endpoint), nil
+}
+
+// NewURL creates an endpoint using sepcific URL settings. Further settings
+// (i.e. a raw body) can be given as URL configs. Template expressions (i.e.
+// {{uuid}}) of the address, headers, cookie, data and body are parsed once
+// here and evaluated for every request.
+func NewURL(requestedURL,
+	method string,
+	data []string,
+	headers []string,
+	rawCookie string,
+	userPass string,
+	urlConfigurations ...func(*URL),
+) (*URL, error) {
+	addrTemplate, err := parseTemplate(requestedURL)
+
+	if err != nil {
+		return nil, fmt.Errorf("Invalid URL template: %v", err)
+	}
+
+	sampleURL := requestedURL
+
+	// A sample of a templated address is validated instead as escaping
+	// would break its expressions.
+	if addrTemplate != nil {
+		sampleURL = addrTemplate.execute(&templateContext{now: time.Now()})
+	}
+
+	parsedURL, err := url.Parse(sampleURL)
 	if err != nil {
 		return nil, fmt.Errorf("Invalid URL provided: %v", err)
 	}
@@ -102,6 +212,16 @@ func WithURLSettings(requestedURL,
 		return nil, errors.New("Only http and https schemes are supported")
 	}
 
+	addr := parsedURL.String()
+
+	if addrTemplate != nil {
+		addr = requestedURL
+
+		if !strings.Contains(addr, "://") {
+			addr = parsedURL.Scheme + "://" + addr
+		}
+	}
+
 	method = strings.ToUpper(method)
 
 	if method == "" {
@@ -115,7 +235,7 @@ func WithURLSettings(requestedURL,
 	}
 
 	endpoint := &URL{
-		Addr:      parsedURL.String(),
+		Addr:      addr,
 		Method:    method,
 		Data:      parsedData,
 		RawCookie: rawCookie,
@@ -144,7 +264,117 @@ func WithURLSettings(requestedURL,
 		}
 	}
 
-	return WithURL(endpoint), nil
+	for _, config := range urlConfigurations {
+		config(endpoint)
+	}
+
+	if len(endpoint.Body) > 0 && len(endpoint.Data) > 0 {
+		return nil, errors.New("Request data can not be used with a raw body")
+	}
+
+	if len(endpoint.Parts) > 0 && (len(endpoint.Body) > 0 || len(endpoint.Data) > 0) {
+		return nil, errors.New("Multipart parts can not be used with request data or a raw body")
+	}
+
+	if len(endpoint.Parts) > 0 && method != http.MethodPost && method != http.MethodPut && method != http.MethodPatch {
+		return nil, errors.New("Multipart parts are only allowed with POST, PUT and PATCH request methods")
+	}
+
+	endpoint.templates, err = newURLTemplates(endpoint)
+
+	if err != nil {
+		return nil, fmt.Errorf("Invalid template: %v", err)
+	}
+
+	return endpoint, nil
+}
+
+// WithBody creates a URL config to send a raw request body. The body is sent
+// as is for every request. When no content type is given, valid JSON bodies
+// are sent as application/json and the rest as application/octet-stream.
+func WithBody(body []byte, contentType string) func(*URL) {
+	if contentType == "" {
+		contentType = "application/octet-stream"
+
+		if json.Valid(body) {
+			contentType = "application/json"
+		}
+	}
+
+	return func(u *URL) {
+		u.Body = body
+		u.ContentType = contentType
+	}
+}
+
+// WithBodyString creates a URL config to send a raw request body. A body
+// starting with @ is read from the file with the given path (i.e.
+// @body.json) and sent as is.
+func WithBodyString(body, contentType string) (func(*URL), error) {
+	if !strings.HasPrefix(body, "@") {
+		return WithBody([]byte(body), contentType), nil
+	}
+
+	return WithBodyFile(body[1:], contentType, false)
+}
+
+// WithBodyFile creates a URL config to send the content of a file as the raw
+// request body. The content is sent as is (even binary content containing
+// {{) unless template is set, in which case its template expressions are
+// evaluated for every request.
+func WithBodyFile(path, contentType string, template bool) (func(*URL), error) {
+	content, err := ioutil.ReadFile(path)
+
+	if err != nil {
+		return nil, fmt.Errorf("Could not read the body file: %v", err)
+	}
+
+	withBody := WithBody(content, contentType)
+
+	return func(u *URL) {
+		withBody(u)
+		u.RawBody = !template
+	}, nil
+}
+
+// WithPart creates a URL config to add a part to a multipart/form-data request
+// body.
+func WithPart(part *Part) func(*URL) {
+	return func(u *URL) {
+		u.Parts = append(u.Parts, part)
+	}
+}
+
+// WithPartString creates a URL config to add a part in the curl format (i.e.
+// 'name=value', 'name=@path' or 'name=@path;type=image/png;filename=a.png').
+// Files are checked to exist but they are only read when requests are sent.
+func WithPartString(part string) (func(*URL), error) {
+	p, err := parsePart(part)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return WithPart(p), nil
+}
+
+// WithAssertion creates a URL config to add a check of the responses.
+func WithAssertion(a *Assertion) func(*URL) {
+	return func(u *URL) {
+		u.Assertions = append(u.Assertions, a)
+	}
+}
+
+// WithAssertionString creates a URL config to add a check of the responses
+// using a string in the format of type:expression (i.e. json:data.status=ok).
+func WithAssertionString(assertion string) (func(*URL), error) {
+	a, err := NewAssertionString(assertion)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return WithAssertion(a), nil
 }
 
 // WithConnectionTimeout sets connection timeout.
@@ -161,6 +391,187 @@ func WithResponseTimeout(t time.Duration) func(*Bench) {
 	}
 }
 
+// WithDisableKeepAlives creates a config to open a new connection for every
+// request.
+func WithDisableKeepAlives() func(*Bench) {
+	return func(b *Bench) {
+		b.DisableKeepAlives = true
+	}
+}
+
+// WithMaxIdleConnsPerHost creates a config to set the maximum number of idle
+// connections kept per host.
+func WithMaxIdleConnsPerHost(n int) func(*Bench) {
+	return func(b *Bench) {
+		b.MaxIdleConnsPerHost = n
+	}
+}
+
+// WithMaxConnsPerHost creates a config to set the maximum number of
+// connections per host. Requests exceeding it wait for a connection.
+func WithMaxConnsPerHost(n int) func(*Bench) {
+	return func(b *Bench) {
+		b.MaxConnsPerHost = n
+	}
+}
+
+// WithIdleConnTimeout creates a config to set the time after which idle
+// connections are closed.
+func WithIdleConnTimeout(t time.Duration) func(*Bench) {
+	return func(b *Bench) {
+		b.IdleConnTimeout = t
+	}
+}
+
+// WithDisableRedirects creates a config to report redirect responses instead
+// of following them.
+func WithDisableRedirects() func(*Bench) {
+	return func(b *Bench) {
+		b.DisableRedirects = true
+	}
+}
+
+// WithMaxRedirects creates a config to set the maximum number of redirects to
+// follow.
+func WithMaxRedirects(n int) func(*Bench) {
+	return func(b *Bench) {
+		b.MaxRedirects = n
+	}
+}
+
+// WithTLSConfig sets the TLS configuration of the connections. The TLS
+// options below modify this configuration.
+func WithTLSConfig(c *tls.Config) func(*Bench) {
+	return func(b *Bench) {
+		b.TLSConfig = c
+	}
+}
+
+// WithCACert creates a config to verify the servers using the certificates of
+// the given PEM file instead of the system ones (i.e. a private CA).
+func WithCACert(path string) (func(*Bench), error) {
+	content, err := ioutil.ReadFile(path)
+
+	if err != nil {
+		return nil, fmt.Errorf("Could not read the CA certificate: %v", err)
+	}
+
+	pool := x509.NewCertPool()
+
+	if !pool.AppendCertsFromPEM(content) {
+		return nil, fmt.Errorf("No certificate found in %s", path)
+	}
+
+	return func(b *Bench) {
+		b.getTLSConfig().RootCAs = pool
+	}, nil
+}
+
+// WithClientCert creates a config to authenticate with a client certificate
+// (mTLS) using the given PEM certificate and key files.
+func WithClientCert(certPath, keyPath string) (func(*Bench), error) {
+	cert, err := tls.LoadX509KeyPair(certPath, keyPath)
+
+	if err != nil {
+		return nil, fmt.Errorf("Could not load the client certificate: %v", err)
+	}
+
+	return func(b *Bench) {
+		c := b.getTLSConfig()
+		c.Certificates = append(c.Certificates, cert)
+	}, nil
+}
+
+// WithInsecureSkipVerify creates a config to accept any certificate of the
+// servers (i.e. self-signed certificates).
+func WithInsecureSkipVerify() func(*Bench) {
+	return func(b *Bench) {
+		b.getTLSConfig().InsecureSkipVerify = true
+	}
+}
+
+// WithServerName creates a config to override the server name which is sent
+// as SNI and used to verify the certificates of the servers.
+func WithServerName(name string) func(*Bench) {
+	return func(b *Bench) {
+		b.getTLSConfig().ServerName = name
+	}
+}
+
+// WithTLSVersions creates a config to set the minimum and maximum TLS versions
+// (i.e. 1.2 or 1.3). An empty version means the default one.
+func WithTLSVersions(min, max string) (func(*Bench), error) {
+	minVersion, err := parseTLSVersion(min)
+
+	if err != nil {
+		return nil, err
+	}
+
+	maxVersion, err := parseTLSVersion(max)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if minVersion != 0 && maxVersion != 0 && minVersion > maxVersion {
+		return nil, fmt.Errorf("Minimum TLS version %s is greater than maximum TLS version %s", min, max)
+	}
+
+	return func(b *Bench) {
+		c := b.getTLSConfig()
+		c.MinVersion, c.MaxVersion = minVersion, maxVersion
+	}, nil
+}
+
+// WithCipherSuites creates a config to set the cipher suites of TLS 1.0-1.2
+// using their names (i.e. TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256). TLS 1.3
+// cipher suites are not configurable.
+func WithCipherSuites(names []string) (func(*Bench), error) {
+	suites := make(map[string]uint16)
+
+	for _, suite := range append(tls.CipherSuites(), tls.InsecureCipherSuites()...) {
+		suites[suite.Name] = suite.ID
+	}
+
+	ids := make([]uint16, 0, len(names))
+
+	for _, name := range names {
+		id, ok := suites[strings.TrimSpace(name)]
+
+		if !ok {
+			return nil, fmt.Errorf("Unknown cipher suite: %s", name)
+		}
+
+		ids = append(ids, id)
+	}
+
+	return func(b *Bench) {
+		b.getTLSConfig().CipherSuites = ids
+	}, nil
+}
+
+// WithProtocol creates a config to set the protocol of the requests (i.e.
+// http1, http2 or h2c).
+func WithProtocol(protocol string) (func(*Bench), error) {
+	switch protocol {
+	case ProtocolHTTP1, ProtocolHTTP2, ProtocolH2C:
+	default:
+		return nil, fmt.Errorf("Wrong protocol: %s (Accepted protocols are http1, http2 and h2c)", protocol)
+	}
+
+	return func(b *Bench) {
+		b.Protocol = protocol
+	}, nil
+}
+
+// WithStreamsPerConnection creates a config to set the number of concurrent
+// streams per HTTP/2 connection.
+func WithStreamsPerConnection(n int) func(*Bench) {
+	return func(b *Bench) {
+		b.StreamsPerConnection = n
+	}
+}
+
 // WithRawCookie sets a raw cookie string.
 // Note: This will be used for all the provided urls.
 func WithRawCookie(cookie string) func(*Bench) {
@@ -186,6 +597,10 @@ func WithHeaderString(header string) (func(*Bench), error) {
 		return nil, err
 	}
 
+	if _, err := parseTemplate(value); err != nil {
+		return nil, err
+	}
+
 	return WithHeader(key, value), nil
 }
 
@@ -224,6 +639,21 @@ func WithHeaderString(header string) (func(*Bench), error) {
 	}, nil
 }*/
 
+// WithFeeder sets the feeder of the {{feed column}} template expressions.
+func WithFeeder(f *Feeder) func(*Bench) {
+	return func(b *Bench) {
+		b.Feeder = f
+	}
+}
+
+// WithScenario adds a scenario which is run on a loop by each of the
+// concurrent virtual users.
+func WithScenario(s *Scenario) func(*Bench) {
+	return func(b *Bench) {
+		b.Scenarios = append(b.Scenarios, s)
+	}
+}
+
 // WithReport sets a result report
 func WithReport(report report.Report) func(*Bench) {
 	return func(b *Bench) {
@@ -231,6 +661,23 @@ func WithReport(report report.Report) func(*Bench) {
 	}
 }
 
+func parseTLSVersion(version string) (uint16, error) {
+	switch strings.TrimPrefix(strings.ToLower(version), "tls") {
+	case "":
+		return 0, nil
+	case "1.0":
+		return tls.VersionTLS10, nil
+	case "1.1":
+		return tls.VersionTLS11, nil
+	case "1.2":
+		return tls.VersionTLS12, nil
+	case "1.3":
+		return tls.VersionTLS13, nil
+	}
+
+	return 0, fmt.Errorf("Wrong TLS version: %s (Accepted versions are 1.0, 1.1, 1.2 and 1.3)", version)
+}
+
 func parseData(formData []string, method string) (map[string]string, error) {
 	data := make(map[string]string)
 
@@ -253,6 +700,105 @@ func parseData(formData []string, method string) (map[string]string, error) {
 	return data, nil
 }
 
+func parseRate(rate string) (float64, error) {
+	parts := strings.Split(rate, "/")
+
+	if len(parts) > 2 {
+		return 0, fmt.Errorf("Wrong rate format: %s", rate)
+	}
+
+	n, err := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("Wrong rate format: %s", rate)
+	}
+
+	if len(parts) == 1 {
+		return n, nil
+	}
+
+	unit := strings.TrimSpace(parts[1])
+
+	if unit != "" && (unit[0] < '0' || unit[0] > '9') {
+		unit = "1" + unit
+	}
+
+	per, err := time.ParseDuration(unit)
+
+	if err != nil || per <= 0 {
+		return 0, fmt.Errorf("Wrong rate unit: %s", rate)
+	}
+
+	return n / per.Seconds(), nil
+}
+
+func parseStage(stage string) (*Stage, error) {
+	m := regexp.MustCompile(`^(?:([^=]+)=)?([^:]+):(\d+)(?:-(\d+))?$`)
+
+	matches := m.FindStringSubmatch(strings.TrimSpace(stage))
+
+	if matches == nil {
+		return nil, fmt.Errorf("Wrong stage format: %s", stage)
+	}
+
+	d, err := time.ParseDuration(matches[2])
+
+	if err != nil || d <= 0 {
+		return nil, fmt.Errorf("Wrong stage duration: %s", stage)
+	}
+
+	s := &Stage{Name: matches[1], Duration: d}
+	s.From, _ = strconv.Atoi(matches[3])
+	s.To = s.From
+
+	if matches[4] != "" {
+		s.To, _ = strconv.Atoi(matches[4])
+	}
+
+	return s, nil
+}
+
+func parsePart(part string) (*Part, error) {
+	keyValue := strings.SplitN(part, "=", 2)
+
+	if len(keyValue) != 2 || keyValue[0] == "" {
+		return nil, fmt.Errorf("Wrong part format: %s", part)
+	}
+
+	p := &Part{Name: keyValue[0]}
+
+	if !strings.HasPrefix(keyValue[1], "@") {
+		p.Value = keyValue[1]
+		return p, nil
+	}
+
+	options := strings.Split(keyValue[1][1:], ";")
+	p.File = options[0]
+
+	for _, option := range options[1:] {
+		optionKeyValue := strings.SplitN(option, "=", 2)
+
+		if len(optionKeyValue) != 2 {
+			return nil, fmt.Errorf("Wrong part option: %s", option)
+		}
+
+		switch optionKeyValue[0] {
+		case "type":
+			p.ContentType = optionKeyValue[1]
+		case "filename":
+			p.FileName = optionKeyValue[1]
+		default:
+			return nil, fmt.Errorf("Wrong part option: %s", option)
+		}
+	}
+
+	if _, err := os.Stat(p.File); err != nil {
+		return nil, fmt.Errorf("Could not read the file of part %s: %v", p.Name, err)
+	}
+
+	return p, nil
+}
+
 func parseUserPass(userPass string) (user, pass string, err error) {
 	m := regexp.MustCompile(`^([^:]+):(.+)$`)
 	if !m.MatchString(userPass) {
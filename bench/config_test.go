@@ -2,6 +2,8 @@ package bench
 
 import (
 	"bytes"
+	"crypto/tls"
+	"net/http"
 	"testing"
 	"time"
 
@@ -78,6 +80,7 @@ func TestConfigurations(t *testing.T) {
 	configurations := []func(*Bench){
 		WithConcurrency(2),
 		WithRequests(4),
+		WithDuration(time.Minute),
 		WithURL(u),
 		WithAuth("user", "pass"),
 		WithOutput(&buf),
@@ -106,6 +109,10 @@ func checkBenchGeneralConfig(b *Bench, t *testing.T) {
 		t.Error("Number of requests is not set as expected")
 	}
 
+	if b.Duration != time.Minute {
+		t.Error("Duration is not set as expected")
+	}
+
 	if b.Auth.Username != "user" || b.Auth.Password != "pass" {
 		t.Error("Auth is not set as expected")
 	}
@@ -148,3 +155,172 @@ func checkBenchURLs(b *Bench, t *testing.T) {
 		t.Errorf("Success status codes were expected to be set as '100' and '101' but got %v", b.SuccessStatusCodes)
 	}
 }
+
+func TestRate(t *testing.T) {
+	expected := map[string]float64{
+		"500":      500,
+		"500/s":    500,
+		"30/m":     0.5,
+		"10/100ms": 100,
+		"2/2s":     1,
+	}
+
+	for rate, expectedRate := range expected {
+		config, err := WithRateString(rate)
+
+		if err != nil {
+			t.Errorf("Unexpected error for %q: %v", rate, err)
+			continue
+		}
+
+		b := NewBench(config)
+
+		if b.Rate != expectedRate {
+			t.Errorf("Expected rate of %v for %q but got %v", expectedRate, rate, b.Rate)
+		}
+
+		if b.MaxInFlight != defaultMaxInFlight {
+			t.Errorf("Expected default max in-flight requests but got %d", b.MaxInFlight)
+		}
+	}
+
+	for _, rate := range []string{"", "abc/s", "-1/s", "10/x", "10/s/s", "10/0s"} {
+		if _, err := WithRateString(rate); err == nil {
+			t.Errorf("Expected an error for %q", rate)
+		}
+	}
+}
+
+func TestStage(t *testing.T) {
+	expected := map[string]*Stage{
+		"ramp=2m:10-200": {Name: "ramp", Duration: 2 * time.Minute, From: 10, To: 200},
+		"5m:200":         {Duration: 5 * time.Minute, From: 200, To: 200},
+		"spike=30s:500":  {Name: "spike", Duration: 30 * time.Second, From: 500, To: 500},
+		"down=1m:100-0":  {Name: "down", Duration: time.Minute, From: 100, To: 0},
+	}
+
+	for stage, expectedStage := range expected {
+		parsedStage, err := parseStage(stage)
+
+		if err != nil {
+			t.Errorf("Unexpected error for %q: %v", stage, err)
+			continue
+		}
+
+		if *parsedStage != *expectedStage {
+			t.Errorf("Expected %+v for %q but got %+v", expectedStage, stage, parsedStage)
+		}
+	}
+
+	for _, stage := range []string{"", "2m", "2m:", "2m:a-b", "x:10", "0s:10", "2m:10-"} {
+		if _, err := WithStageString(stage); err == nil {
+			t.Errorf("Expected an error for %q", stage)
+		}
+	}
+
+	b := NewBench(WithStage(&Stage{Duration: time.Second, From: 5}), WithStage(&Stage{Name: "named", Duration: time.Second, From: 1, To: 2}))
+
+	if b.Stages[0].Name != "stage 1" || b.Stages[0].To != 0 || b.Stages[1].Name != "named" {
+		t.Errorf("Unexpected stage defaults: %+v %+v", b.Stages[0], b.Stages[1])
+	}
+
+	if b.Requests != 0 {
+		t.Errorf("Expected no request limit with stages but got %d", b.Requests)
+	}
+}
+
+func TestTLSOptions(t *testing.T) {
+	versions, err := WithTLSVersions("1.2", "TLS1.3")
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	suites, err := WithCipherSuites([]string{"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256"})
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	b := NewBench(versions, suites, WithInsecureSkipVerify(), WithServerName("example.com"))
+
+	if b.TLSConfig.MinVersion != tls.VersionTLS12 || b.TLSConfig.MaxVersion != tls.VersionTLS13 {
+		t.Errorf("Unexpected TLS versions: %x %x", b.TLSConfig.MinVersion, b.TLSConfig.MaxVersion)
+	}
+
+	if len(b.TLSConfig.CipherSuites) != 1 || b.TLSConfig.CipherSuites[0] != tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256 {
+		t.Errorf("Unexpected cipher suites: %v", b.TLSConfig.CipherSuites)
+	}
+
+	if !b.TLSConfig.InsecureSkipVerify || b.TLSConfig.ServerName != "example.com" {
+		t.Errorf("Unexpected TLS config: %+v", b.TLSConfig)
+	}
+
+	if NewBench().TLSConfig != nil {
+		t.Error("Did not expect a TLS config without TLS options")
+	}
+
+	if _, err := WithTLSVersions("1.4", ""); err == nil || err.Error() != "Wrong TLS version: 1.4 (Accepted versions are 1.0, 1.1, 1.2 and 1.3)" {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	if _, err := WithTLSVersions("1.3", "1.2"); err == nil || err.Error() != "Minimum TLS version 1.3 is greater than maximum TLS version 1.2" {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	if _, err := WithCipherSuites([]string{"TLS_WRONG"}); err == nil || err.Error() != "Unknown cipher suite: TLS_WRONG" {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	if _, err := WithCACert("/non/existent/ca.pem"); err == nil {
+		t.Error("Expected an error for a missing CA certificate")
+	}
+
+	if _, err := WithClientCert("/non/existent/cert.pem", "/non/existent/key.pem"); err == nil {
+		t.Error("Expected an error for a missing client certificate")
+	}
+}
+
+func TestProtocolOptions(t *testing.T) {
+	if b := NewBench(); b.Protocol != ProtocolHTTP1 {
+		t.Errorf("Expected HTTP/1.1 by default but got %s", b.Protocol)
+	}
+
+	protocol, err := WithProtocol(ProtocolH2C)
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if b := NewBench(protocol, WithStreamsPerConnection(10)); b.Protocol != ProtocolH2C || b.StreamsPerConnection != 10 {
+		t.Errorf("Unexpected protocol settings: %s %d", b.Protocol, b.StreamsPerConnection)
+	}
+
+	if _, err := WithProtocol("spdy"); err == nil || err.Error() != "Wrong protocol: spdy (Accepted protocols are http1, http2 and h2c)" {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestConnectionOptions(t *testing.T) {
+	b := NewBench(WithDisableKeepAlives(), WithMaxIdleConnsPerHost(10), WithMaxConnsPerHost(20), WithIdleConnTimeout(time.Minute))
+
+	if !b.DisableKeepAlives || b.MaxIdleConnsPerHost != 10 || b.MaxConnsPerHost != 20 || b.IdleConnTimeout != time.Minute {
+		t.Errorf("Unexpected connection settings: %+v", b)
+	}
+
+	tr := b.getClient().Transport.(*http.Transport)
+
+	if !tr.DisableKeepAlives || tr.MaxIdleConnsPerHost != 10 || tr.MaxConnsPerHost != 20 || tr.IdleConnTimeout != time.Minute {
+		t.Errorf("Unexpected transport settings: %+v", tr)
+	}
+}
+
+func TestRedirectOptions(t *testing.T) {
+	if b := NewBench(); b.DisableRedirects || b.MaxRedirects != defaultMaxRedirects {
+		t.Errorf("Unexpected default redirect settings: %v %d", b.DisableRedirects, b.MaxRedirects)
+	}
+
+	if b := NewBench(WithDisableRedirects(), WithMaxRedirects(3)); !b.DisableRedirects || b.MaxRedirects != 3 {
+		t.Errorf("Unexpected redirect settings: %v %d", b.DisableRedirects, b.MaxRedirects)
+	}
+}
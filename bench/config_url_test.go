@@ -1,6 +1,8 @@
 package bench
 
 import (
+	"io/ioutil"
+	"os"
 	"strings"
 	"testing"
 )
@@ -209,6 +211,152 @@ func TestWrongURLs(t *testing.T) {
 	}
 }
 
+func TestURLBody(t *testing.T) {
+	file, err := ioutil.TempFile("", "gbench-body")
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	defer os.Remove(file.Name())
+
+	file.WriteString("<xml></xml>")
+	file.Close()
+
+	bodyTests := []struct {
+		body, contentType, expectedBody, expectedContentType string
+	}{
+		{`{"foo": "bar"}`, "", `{"foo": "bar"}`, "application/json"},
+		{"raw", "", "raw", "application/octet-stream"},
+		{"@" + file.Name(), "application/xml", "<xml></xml>", "application/xml"},
+	}
+
+	for _, bodyTest := range bodyTests {
+		withBody, err := WithBodyString(bodyTest.body, bodyTest.contentType)
+
+		if err != nil {
+			t.Fatalf("Unexpected error for %s: %v", bodyTest.body, err)
+		}
+
+		urlConfig, err := WithURLSettings("http://www.google.com", "POST", []string{}, []string{}, "", "", withBody)
+
+		if err != nil {
+			t.Fatalf("Unexpected error for %s: %v", bodyTest.body, err)
+		}
+
+		b := NewBench(urlConfig)
+
+		if string(b.URLs[0].Body) != bodyTest.expectedBody || b.URLs[0].ContentType != bodyTest.expectedContentType {
+			t.Errorf("Unexpected body %q with content type %q", b.URLs[0].Body, b.URLs[0].ContentType)
+		}
+	}
+
+	rawFile, err := ioutil.TempFile("", "gbench-body")
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	defer os.Remove(rawFile.Name())
+
+	rawFile.WriteString("id={{seq}}")
+	rawFile.Close()
+
+	for _, template := range []bool{false, true} {
+		withBody, err := WithBodyFile(rawFile.Name(), "text/plain", template)
+
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		urlConfig, err := WithURLSettings("http://www.google.com", "POST", []string{}, []string{}, "", "", withBody)
+
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		b := NewBench(urlConfig)
+		req, _ := b.buildRequest(b.URLs[0], 1)
+		sent, _ := ioutil.ReadAll(req.Body)
+
+		if expected := map[bool]string{false: "id={{seq}}", true: "id=1"}[template]; string(sent) != expected {
+			t.Errorf("Expected to send %q but got %q", expected, sent)
+		}
+	}
+
+	if _, err := WithBodyString("@/non/existent/file", ""); err == nil || !strings.Contains(err.Error(), "Could not read the body file") {
+		t.Errorf("Expected to get an error for a missing file but got %v", err)
+	}
+
+	_, err = WithURLSettings("http://www.google.com", "POST", []string{"foo=bar"}, []string{}, "", "", WithBody([]byte("raw"), ""))
+
+	if err == nil || err.Error() != "Request data can not be used with a raw body" {
+		t.Errorf("Expected to get an error for data with a raw body but got %v", err)
+	}
+}
+
+func TestURLParts(t *testing.T) {
+	file, err := ioutil.TempFile("", "gbench-part")
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	defer os.Remove(file.Name())
+	file.Close()
+
+	partTests := []struct {
+		part     string
+		expected Part
+	}{
+		{"name=gbench", Part{Name: "name", Value: "gbench"}},
+		{"empty=", Part{Name: "empty"}},
+		{"upload=@" + file.Name(), Part{Name: "upload", File: file.Name()}},
+		{"upload=@" + file.Name() + ";type=image/png;filename=a.png", Part{Name: "upload", File: file.Name(), ContentType: "image/png", FileName: "a.png"}},
+	}
+
+	for _, partTest := range partTests {
+		withPart, err := WithPartString(partTest.part)
+
+		if err != nil {
+			t.Fatalf("Unexpected error for %s: %v", partTest.part, err)
+		}
+
+		u := &URL{}
+		withPart(u)
+
+		if len(u.Parts) != 1 || *u.Parts[0] != partTest.expected {
+			t.Errorf("Expected %+v for %s but got %+v", partTest.expected, partTest.part, u.Parts[0])
+		}
+	}
+
+	wrongParts := map[string]string{
+		"name":                                "Wrong part format: name",
+		"=value":                              "Wrong part format: =value",
+		"upload=@" + file.Name() + ";foo=bar": "Wrong part option: foo=bar",
+		"upload=@" + file.Name() + ";type":    "Wrong part option: type",
+		"upload=@/non/existent/file":          "Could not read the file of part upload",
+	}
+
+	for part, expected := range wrongParts {
+		if _, err := WithPartString(part); err == nil || !strings.HasPrefix(err.Error(), expected) {
+			t.Errorf("Expected to get %q for %s but got %v", expected, part, err)
+		}
+	}
+
+	withPart := WithPart(&Part{Name: "name", Value: "gbench"})
+
+	if _, err := WithURLSettings("http://www.google.com", "POST", []string{"foo=bar"}, []string{}, "", "", withPart); err == nil ||
+		err.Error() != "Multipart parts can not be used with request data or a raw body" {
+		t.Errorf("Expected to get an error for parts with data but got %v", err)
+	}
+
+	if _, err := WithURLSettings("http://www.google.com", "GET", []string{}, []string{}, "", "", withPart); err == nil ||
+		err.Error() != "Multipart parts are only allowed with POST, PUT and PATCH request methods" {
+		t.Errorf("Expected to get an error for parts with GET but got %v", err)
+	}
+}
+
 func checkTestResult(b *Bench, t *testing.T) {
 	if len(b.URLs) != len(expectedURLs) {
 		t.Fatalf("Wrong number of urls")
@@ -0,0 +1,55 @@
+package bench
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"syscall"
+
+	"github.com/sasanrose/gbench/report"
+)
+
+// classifyError returns the class of the error of a request.
+func classifyError(err error) string {
+	var dnsErr *net.DNSError
+
+	switch {
+	case errors.Is(err, context.Canceled):
+		return report.ErrorCanceled
+	case errors.As(err, &dnsErr):
+		return report.ErrorDNS
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return report.ErrorConnectionRefused
+	case errors.Is(err, syscall.ECONNRESET):
+		return report.ErrorConnectionReset
+	case isTLSError(err):
+		return report.ErrorTLS
+	case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		return report.ErrorEOF
+	}
+
+	return report.ErrorOther
+}
+
+func isTLSError(err error) bool {
+	var (
+		recordErr       tls.RecordHeaderError
+		alertErr        tls.AlertError
+		verificationErr *tls.CertificateVerificationError
+		authorityErr    x509.UnknownAuthorityError
+		hostnameErr     x509.HostnameError
+		invalidErr      x509.CertificateInvalidError
+	)
+
+	if errors.As(err, &recordErr) || errors.As(err, &alertErr) || errors.As(err, &verificationErr) ||
+		errors.As(err, &authorityErr) || errors.As(err, &hostnameErr) || errors.As(err, &invalidErr) {
+		return true
+	}
+
+	// Some handshake errors are not typed (i.e. a server without TLS).
+	return strings.Contains(err.Error(), "tls: ") || strings.Contains(err.Error(), "HTTP response to HTTPS client")
+}
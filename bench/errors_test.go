@@ -0,0 +1,106 @@
+package bench
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"io"
+	"io/ioutil"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/sasanrose/gbench/report"
+)
+
+func TestClassifyError(t *testing.T) {
+	dial := func(err error) error {
+		return &url.Error{Op: "Get", URL: "http://localhost", Err: &net.OpError{Op: "dial", Net: "tcp", Err: err}}
+	}
+
+	errs := map[error]string{
+		dial(&net.DNSError{Err: "no such host", Name: "wrong.host"}):                        report.ErrorDNS,
+		dial(os.NewSyscallError("connect", syscall.ECONNREFUSED)):                           report.ErrorConnectionRefused,
+		dial(os.NewSyscallError("read", syscall.ECONNRESET)):                                report.ErrorConnectionReset,
+		dial(tls.RecordHeaderError{Msg: "first record does not look like a TLS handshake"}): report.ErrorTLS,
+		&url.Error{Op: "Get", URL: "http://localhost", Err: io.EOF}:                         report.ErrorEOF,
+		&url.Error{Op: "Get", URL: "http://localhost", Err: context.Canceled}:               report.ErrorCanceled,
+		errors.New("http: server gave HTTP response to HTTPS client"):                       report.ErrorTLS,
+		errors.New("unknown"): report.ErrorOther,
+	}
+
+	for err, expected := range errs {
+		if class := classifyError(err); class != expected {
+			t.Errorf("Expected %s for %v but got %s", expected, err, class)
+		}
+	}
+}
+
+func TestExecErrors(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/eof", func(w http.ResponseWriter, r *http.Request) {
+		conn, _, _ := w.(http.Hijacker).Hijack()
+		conn.Close()
+	})
+	mux.HandleFunc("/short", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", "100")
+		w.Write([]byte("short"))
+	})
+
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	listener, _ := net.Listen("tcp", "127.0.0.1:0")
+	refusedURL := "http://" + listener.Addr().String()
+	listener.Close()
+
+	// The certificate of the server is not trusted.
+	tlsServer := httptest.NewUnstartedServer(mux)
+	tlsServer.Config.ErrorLog = log.New(ioutil.Discard, "", 0)
+	tlsServer.StartTLS()
+	defer tlsServer.Close()
+
+	tlsURL := tlsServer.URL
+
+	r := &report.Result{}
+	r.Init(1)
+
+	configurations := []func(*Bench){WithRequests(2), WithReport(r)}
+
+	for _, u := range []string{ts.URL + "/eof", ts.URL + "/short", refusedURL, tlsURL} {
+		withURL, _ := WithURLSettings(u, "GET", []string{}, []string{}, "", "")
+		configurations = append(configurations, withURL)
+	}
+
+	if err := NewBench(configurations...).Exec(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := map[string]string{
+		ts.URL + "/eof":   report.ErrorEOF,
+		ts.URL + "/short": report.ErrorBodyRead,
+		refusedURL:        report.ErrorConnectionRefused,
+		tlsURL:            report.ErrorTLS,
+	}
+
+	for u, class := range expected {
+		errs := r.Errors[u]
+
+		if len(errs) != 1 || errs[class] == nil || errs[class].Count != 2 || len(errs[class].Samples) == 0 {
+			t.Errorf("Expected 2 errors of class %s for %s but got %v", class, u, errs)
+		}
+	}
+
+	if r.FailedResponseStatusCode[ts.URL+"/short"][http.StatusOK] != 2 {
+		t.Errorf("Expected the responses with a short body to fail but got %v", r.FailedResponseStatusCode[ts.URL+"/short"])
+	}
+
+	if r.FailedRequests != 8 {
+		t.Errorf("Expected 8 failed requests but got %d", r.FailedRequests)
+	}
+}
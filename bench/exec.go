@@ -5,80 +5,301 @@ import (
 	"context"
 	"fmt"
 	"io/ioutil"
-	"log"
 	"net"
 	"net/http"
+	"net/http/httptrace"
 	"net/url"
 	"sync"
+	"sync/atomic"
 	"time"
+
+	"github.com/sasanrose/gbench/report"
 )
 
 // Exec executes a benchmark. The context is used to cancel the benchmark at any
-// given time.
+// given time. The benchmark stops when all the requests are sent or when the
+// duration is elapsed, whichever comes first. Requests which are already sent
+// when the duration is elapsed are still waited for.
 func (b *Bench) Exec(ctx context.Context) error {
-	client := b.getClient()
-	remainingRequests := b.Requests
+	if b.templatesErr != nil {
+		return b.templatesErr
+	}
+
+	if err := b.checkScenarios(); err != nil {
+		return err
+	}
+
+	if err := b.checkFeedColumns(); err != nil {
+		return err
+	}
+
+	if err := b.checkProtocol(); err != nil {
+		return err
+	}
+
+	clients := newClientPool(b)
 	t := time.Now()
 
 	b.Report.SetStartTime(t)
 
+	for _, s := range b.Scenarios {
+		steps := make([]string, 0, len(s.Steps))
+
+		for _, step := range s.Steps {
+			steps = append(steps, stepURL(s, step))
+		}
+
+		b.Report.AddScenario(s.Name, steps)
+	}
+
+	if b.ExpectedInterval > 0 {
+		b.Report.SetExpectedInterval(b.ExpectedInterval)
+	}
+
 	defer func() {
 		te := time.Now()
 		b.Report.SetTotalDuration(te.Sub(t))
 		b.Report.SetEndTime(te)
 	}()
 
-	for remainingRequests > 0 {
-		waitChannel := make(chan struct{})
-		doneReqs := b.Requests - remainingRequests
-		b.printOutputMessage(fmt.Sprintf("%d of %d (%.1f%%)\n", doneReqs, b.Requests, float64(doneReqs*b.Requests)/100))
-		go b.runConcurrentJobs(ctx, waitChannel, client, &remainingRequests)
+	if b.Rate > 0 {
+		return b.execOpenModel(ctx, clients, t)
+	}
+
+	return b.execClosedModel(ctx, clients, t)
+}
+
+// execClosedModel runs a pool of workers for each URL and scenario. Each
+// worker sends its next request (or runs its next iteration of a scenario) as
+// soon as its previous one is finished. The size of the pools
+// follows the load stages when they are set.
+func (b *Bench) execClosedModel(ctx context.Context, clients *clientPool, t time.Time) error {
+	pool := newWorkerPool(ctx, b, clients)
+	ticker := time.NewTicker(controlInterval)
+	progress := time.Duration(-1)
+	feederExhausted := b.feederExhausted()
+
+	defer pool.stop()
+	defer ticker.Stop()
+
+	for {
+		if b.durationElapsed(t) {
+			b.Report.SetStopReason(report.StopReasonDuration)
+			return nil
+		}
+
+		concurrency := b.Concurrency
+
+		if len(b.Stages) > 0 {
+			stage, stageConcurrency := b.currentStage(time.Since(t))
+
+			if stage == nil {
+				b.Report.SetStopReason(report.StopReasonStages)
+				return nil
+			}
+
+			pool.setStage(stage.Name)
+			concurrency = stageConcurrency
+		}
+
+		pool.resize(concurrency)
+
+		if elapsed := time.Since(t).Truncate(time.Second); elapsed > progress {
+			progress = elapsed
+			b.printProgressMessage(pool.sentRequests(), time.Since(t))
+		}
+
 		select {
 		case <-ctx.Done():
+			b.Report.SetStopReason(report.StopReasonCanceled)
 			return ctx.Err()
-		case <-waitChannel:
-			continue
+		case <-pool.exhausted:
+			b.Report.SetStopReason(report.StopReasonRequests)
+			return nil
+		case <-feederExhausted:
+			b.Report.SetStopReason(report.StopReasonFeeder)
+			return nil
+		case <-ticker.C:
 		}
 	}
-
-	return nil
 }
 
-func (b *Bench) runConcurrentJobs(ctx context.Context, waitChannel chan struct{}, client *http.Client, remainingRequests *int) {
+// execOpenModel sends requests at fixed intervals no matter how many requests
+// are still in flight. Requests which would exceed MaxInFlight are dropped.
+// Each in-flight request takes one of the MaxInFlight slots.
+func (b *Bench) execOpenModel(ctx context.Context, clients *clientPool, t time.Time) error {
+	interval := time.Duration(float64(time.Second) / b.Rate)
+	slots := make(chan int, b.MaxInFlight)
+	timer := time.NewTimer(0)
 	wg := &sync.WaitGroup{}
-	remainingConcurrent := b.Concurrency
-	for remainingConcurrent > 0 && *remainingRequests > 0 {
+
+	defer wg.Wait()
+	defer timer.Stop()
+
+	for slot := 0; slot < b.MaxInFlight; slot++ {
+		slots <- slot
+	}
+
+	for scheduled := 0; b.Requests == 0 || scheduled < b.Requests; scheduled++ {
+		// Scheduling is based on the start time so that a late tick does not
+		// lower the rate of the following ones.
+		timer.Reset(time.Until(t.Add(time.Duration(scheduled) * interval)))
+
+		select {
+		case <-ctx.Done():
+			b.Report.SetStopReason(report.StopReasonCanceled)
+			return ctx.Err()
+		case <-timer.C:
+		}
+
+		if b.durationElapsed(t) {
+			b.Report.SetStopReason(report.StopReasonDuration)
+			return nil
+		}
+
+		if interval*time.Duration(scheduled)%time.Second < interval {
+			b.printProgressMessage(scheduled, time.Since(t))
+		}
+
 		for _, url := range b.URLs {
-			req := b.buildRequest(url)
-			req = req.WithContext(ctx)
-			wg.Add(1)
-			go b.runBench(wg, client, req)
+			select {
+			case slot := <-slots:
+				req, err := b.buildRequest(url, 0)
+
+				if err != nil {
+					slots <- slot
+					b.addRequestError(url.Addr, err, "")
+					continue
+				}
+
+				if req == nil {
+					slots <- slot
+					b.Report.SetStopReason(report.StopReasonFeeder)
+					return nil
+				}
+
+				req = req.WithContext(ctx)
+				wg.Add(1)
+				go func(url *URL, slot int) {
+					defer wg.Done()
+					defer func() { slots <- slot }()
+					b.runBench(clients.get(slot), req, url.Addr, url.Assertions, "")
+				}(url, slot)
+			default:
+				b.printOutputMessage(fmt.Sprintf("Dropped request for %s: %d requests in flight\n", url.Addr, b.MaxInFlight))
+				b.Report.AddDroppedRequest(url.Addr)
+			}
+		}
+	}
+
+	b.Report.SetStopReason(report.StopReasonRequests)
+
+	return nil
+}
+
+// checkFeedColumns checks that the columns used by the templates exist in the
+// feeder.
+func (b *Bench) checkFeedColumns() error {
+	columns := b.templates.feedColumns()
+
+	for _, u := range b.URLs {
+		columns = append(columns, u.templates.feedColumns()...)
+	}
+
+	for _, s := range b.Scenarios {
+		for _, step := range s.Steps {
+			columns = append(columns, step.URL.templates.feedColumns()...)
+		}
+	}
+
+	for _, column := range columns {
+		if b.Feeder == nil {
+			return fmt.Errorf("No feeder is set for {{feed %s}}", column)
+		}
+
+		if !b.Feeder.hasColumn(column) {
+			return fmt.Errorf("Feeder has no column %s", column)
+		}
+	}
+
+	return nil
+}
+
+// feederExhausted returns a channel which is closed when all the rows of a
+// once feeder are used. It returns nil (which blocks forever) otherwise.
+func (b *Bench) feederExhausted() chan struct{} {
+	if b.Feeder == nil || b.Feeder.Mode != FeederOnce {
+		return nil
+	}
+
+	return b.Feeder.exhausted
+}
+
+// currentStage returns the stage and its concurrency after the given elapsed
+// time. It returns nil when all the stages are finished.
+func (b *Bench) currentStage(elapsed time.Duration) (*Stage, int) {
+	for _, stage := range b.Stages {
+		if elapsed < stage.Duration {
+			concurrency := stage.From + int(float64(stage.To-stage.From)*float64(elapsed)/float64(stage.Duration))
+
+			if concurrency < 1 {
+				concurrency = 1
+			}
+
+			return stage, concurrency
 		}
-		(*remainingRequests)--
-		remainingConcurrent--
+
+		elapsed -= stage.Duration
 	}
-	wg.Wait()
-	close(waitChannel)
+
+	return nil, 0
+}
+
+func (b *Bench) durationElapsed(t time.Time) bool {
+	return b.Duration > 0 && time.Since(t) >= b.Duration
+}
+
+// response contains what the steps of a scenario need from a received
+// response.
+type response struct {
+	header  http.Header
+	cookies []*http.Cookie
+	body    []byte
+	failed  bool
 }
 
-func (b *Bench) runBench(wg *sync.WaitGroup, client *http.Client, req *http.Request) {
-	defer wg.Done()
+// runBench sends a request and reports it under reqURL. Templated URLs are
+// reported under their template instead of every evaluated address. Responses
+// whose body can not be read or with a successful status code which fail any
+// of the assertions are reported as failed. It returns nil when no response is
+// received.
+func (b *Bench) runBench(client *http.Client, req *http.Request, reqURL string, assertions []*Assertion, stage string) *response {
+	trace := &requestTrace{}
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), trace.clientTrace()))
+
+	b.Report.AddSentRequest(reqURL)
 
 	tr := time.Now()
 	resp, err := client.Do(req)
 	responseTime := time.Since(tr)
-	reqURL := req.URL.String()
+
+	if reused, ok := trace.connection(); ok {
+		b.Report.AddConnection(reqURL, reused)
+	}
 
 	if err != nil {
 		if err, ok := err.(*url.Error); ok && err.Timeout() {
 			b.printOutputMessage(fmt.Sprintf("Timed out request for %s: %v\n", reqURL, err))
 			b.Report.AddTimedoutResponse(reqURL)
-			return
+			b.addStageResponse(stage, 0, false, true)
+			return nil
 		}
 
 		b.printOutputMessage(fmt.Sprintf("Error for %s: %v\n", reqURL, err))
 		b.Report.AddFailedResponse(reqURL)
-		return
+		b.Report.AddError(reqURL, classifyError(err), err.Error())
+		b.addStageResponse(stage, 0, true, false)
+		return nil
 	}
 
 	defer resp.Body.Close()
@@ -88,12 +309,58 @@ func (b *Bench) runBench(wg *sync.WaitGroup, client *http.Client, req *http.Requ
 
 	if err == nil {
 		contentLength = len(body)
+	} else {
+		b.printOutputMessage(fmt.Sprintf("Could not read the body for %s: %v\n", reqURL, err))
+		b.Report.AddError(reqURL, report.ErrorBodyRead, err.Error())
+	}
+
+	b.Report.AddPhaseTimes(reqURL, trace.phaseTimes(time.Now()))
+	b.Report.AddResponseProtocol(reqURL, resp.Proto)
+
+	if redirects := countRedirects(resp); redirects > 0 {
+		b.Report.AddRedirects(reqURL, redirects, resp.Request.URL.String())
 	}
 
+	received := &response{header: resp.Header, cookies: resp.Cookies(), body: body}
+	received.failed = err != nil || b.isFailed(resp.StatusCode) || !b.checkAssertions(reqURL, assertions, received, responseTime)
+
 	b.Report.AddResponseTime(reqURL, responseTime)
 	b.Report.AddReceivedDataLength(reqURL, int64(contentLength))
-	b.Report.AddResponseStatusCode(reqURL, resp.StatusCode, b.isFailed(resp.StatusCode))
+	b.Report.AddResponseStatusCode(reqURL, resp.StatusCode, received.failed)
+	b.addStageResponse(stage, responseTime, received.failed, false)
 	b.printOutputMessage(fmt.Sprintf("Received response for sent requests to %s in %v. Status: %s\n", reqURL, responseTime, http.StatusText(resp.StatusCode)))
+
+	return received
+}
+
+// checkAssertions checks a response against all the assertions and reports
+// the failed ones. It returns false when any of them fails.
+func (b *Bench) checkAssertions(reqURL string, assertions []*Assertion, resp *response, responseTime time.Duration) bool {
+	passed := true
+
+	for _, assertion := range assertions {
+		if err := assertion.check(resp, responseTime); err != nil {
+			b.printOutputMessage(fmt.Sprintf("Failed assertion %s for %s: %v\n", assertion, reqURL, err))
+			b.Report.AddFailedAssertion(reqURL, assertion.String())
+			passed = false
+		}
+	}
+
+	return passed
+}
+
+// addRequestError reports a request which could not be created as failed.
+func (b *Bench) addRequestError(reqURL string, err error, stage string) {
+	b.printOutputMessage(fmt.Sprintf("Could not create request for %s: %v\n", reqURL, err))
+	b.Report.AddFailedResponse(reqURL)
+	b.Report.AddError(reqURL, report.ErrorOther, err.Error())
+	b.addStageResponse(stage, 0, true, false)
+}
+
+func (b *Bench) addStageResponse(stage string, responseTime time.Duration, failed, timedOut bool) {
+	if stage != "" {
+		b.Report.AddStageResponse(stage, responseTime, failed, timedOut)
+	}
 }
 
 func (b *Bench) printOutputMessage(msg string) {
@@ -104,6 +371,15 @@ func (b *Bench) printOutputMessage(msg string) {
 	}
 }
 
+func (b *Bench) printProgressMessage(sentRequests int, elapsed time.Duration) {
+	if b.Requests == 0 {
+		b.printOutputMessage(fmt.Sprintf("%d sent in %v of %v\n", sentRequests, elapsed.Truncate(time.Millisecond), b.Duration))
+		return
+	}
+
+	b.printOutputMessage(fmt.Sprintf("%d of %d (%.1f%%)\n", sentRequests, b.Requests, float64(sentRequests*100)/float64(b.Requests)))
+}
+
 func (b *Bench) isFailed(statusCode int) bool {
 	for _, code := range b.SuccessStatusCodes {
 		if statusCode == code {
@@ -115,37 +391,85 @@ func (b *Bench) isFailed(statusCode int) bool {
 }
 
 func (b *Bench) getClient() *http.Client {
+	// Dialing with a context lets httptrace report the connect phase. A zero
+	// timeout means no timeout.
+	dialer := &net.Dialer{Timeout: b.ConnectionTimeout}
+
 	tr := &http.Transport{
-		Dial: b.getDial(),
+		DialContext:         dialer.DialContext,
+		DisableKeepAlives:   b.DisableKeepAlives,
+		MaxIdleConnsPerHost: b.MaxIdleConnsPerHost,
+		MaxConnsPerHost:     b.MaxConnsPerHost,
+		IdleConnTimeout:     b.IdleConnTimeout,
 	}
 
 	if b.ResponseTimeout > 0 {
 		tr.ResponseHeaderTimeout = b.ResponseTimeout
 	}
 
+	if b.TLSConfig != nil {
+		tr.TLSClientConfig = b.TLSConfig
+	}
+
+	b.setProtocols(tr)
+
 	if b.Proxy != "" {
 		p, _ := url.Parse(b.Proxy)
 		tr.Proxy = http.ProxyURL(p)
 	}
 
-	return &http.Client{Transport: tr}
+	return &http.Client{Transport: tr, CheckRedirect: b.checkRedirect}
+}
+
+// buildRequest builds the next request of a URL. Templates are evaluated with
+// the id of the worker sending the request (0 when a rate is set) and the next
+// row of the feeder. It returns a nil request without an error when all the
+// rows of a once feeder are used.
+func (b *Bench) buildRequest(u *URL, worker int) (*http.Request, error) {
+	var ctx *templateContext
+
+	if u.templates != nil || b.templates != nil || b.Feeder != nil {
+		var ok bool
+
+		if ctx, ok = b.newTemplateContext(worker); !ok {
+			return nil, nil
+		}
+	}
+
+	return b.renderRequest(u, ctx)
 }
 
-func (b *Bench) getDial() func(network, addr string) (net.Conn, error) {
-	if b.ConnectionTimeout > 0 {
-		return func(network, addr string) (net.Conn, error) {
-			return net.DialTimeout(network, addr, b.ConnectionTimeout)
+// newTemplateContext creates the context of the templates of a request. It
+// returns false when all the rows of a once feeder are used.
+func (b *Bench) newTemplateContext(worker int) (*templateContext, bool) {
+	ctx := &templateContext{seq: atomic.AddInt64(&b.seq, 1), worker: worker, now: time.Now()}
+
+	if b.Feeder != nil {
+		row, ok := b.Feeder.row()
+
+		if !ok {
+			return nil, false
 		}
+
+		ctx.row = row
 	}
 
-	return net.Dial
+	return ctx, true
 }
 
-func (b *Bench) buildRequest(u *URL) *http.Request {
+// renderRequest creates the request of a URL evaluating its templates with
+// the given context. Templates are not evaluated when the context is nil. An
+// error is returned when the request can not be created (i.e. a template
+// evaluates to an invalid address).
+func (b *Bench) renderRequest(u *URL, ctx *templateContext) (*http.Request, error) {
+	if ctx != nil {
+		u = u.templates.render(u, ctx)
+	}
+
 	req, err := b.newRequest(u)
 
 	if err != nil {
-		log.Fatalf("Could not create request for %s: %v", u, err)
+		return nil, err
 	}
 
 	auth := b.getAuth(u)
@@ -158,22 +482,39 @@ func (b *Bench) buildRequest(u *URL) *http.Request {
 		req.Header.Add("User-Agent", "Gbench")
 	}
 
-	headers := b.getHeaders(u)
+	headers := b.getHeaders(u, ctx)
 
 	for key, value := range headers {
 		req.Header.Add(key, value)
 	}
 
-	rawCookie := b.getRawCookie(u)
+	rawCookie := b.getRawCookie(u, ctx)
 
 	if rawCookie != "" {
 		req.Header.Add("Set-Cookie", rawCookie)
 	}
 
-	return req
+	return req, nil
 }
 
 func (b *Bench) newRequest(u *URL) (*http.Request, error) {
+	if len(u.Parts) > 0 {
+		return newMultipartRequest(u)
+	}
+
+	if len(u.Body) > 0 {
+		// The reader shares the body so it is never copied.
+		req, err := http.NewRequest(u.Method, u.Addr, bytes.NewReader(u.Body))
+
+		if err != nil {
+			return nil, err
+		}
+
+		req.Header.Add("Content-Type", u.ContentType)
+
+		return req, nil
+	}
+
 	if len(u.Data) == 0 ||
 		(u.Method != http.MethodPost && u.Method != http.MethodPatch && u.Method != http.MethodPut) {
 		return http.NewRequest(u.Method, u.Addr, nil)
@@ -204,9 +545,9 @@ func (b *Bench) getAuth(u *URL) *Auth {
 	return b.Auth
 }
 
-func (b *Bench) getHeaders(u *URL) map[string]string {
+func (b *Bench) getHeaders(u *URL, ctx *templateContext) map[string]string {
 	headers := make(map[string]string)
-	for key, value := range b.Headers {
+	for key, value := range b.templates.renderHeaders(b.Headers, ctx) {
 		headers[key] = value
 	}
 
@@ -217,10 +558,10 @@ func (b *Bench) getHeaders(u *URL) map[string]string {
 	return headers
 }
 
-func (b *Bench) getRawCookie(u *URL) string {
+func (b *Bench) getRawCookie(u *URL, ctx *templateContext) string {
 	if u.RawCookie != "" {
 		return u.RawCookie
 	}
 
-	return b.RawCookie
+	return b.templates.renderRawCookie(b.RawCookie, ctx)
 }
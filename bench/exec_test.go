@@ -3,10 +3,25 @@ package bench
 import (
 	"bytes"
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/sasanrose/gbench/report"
 )
@@ -22,6 +37,7 @@ type testRequest struct {
 	data         map[string]string
 	headers      map[string]string
 	cookie       string
+	body         string
 }
 
 type expectedResult struct {
@@ -62,6 +78,10 @@ func (h *testHTTP) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		testReq.headers[k] = v[0]
 	}
 
+	body, _ := ioutil.ReadAll(r.Body)
+	testReq.body = string(body)
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+
 	r.ParseForm()
 	for k, v := range r.PostForm {
 		testReq.data[k] = v[0]
@@ -197,6 +217,621 @@ func TestExec(t *testing.T) {
 	checkRequest(t, hCreated, expectedRequest)
 }
 
+func TestExecBody(t *testing.T) {
+	h := newTestHTTP(http.StatusOK)
+	ts := httptest.NewServer(h)
+	defer ts.Close()
+
+	r := &report.Result{}
+	r.Init(1)
+
+	body := `{"name": "gbench"}`
+	withURL, err := WithURLSettings(ts.URL, "PUT", []string{}, []string{}, "", "", WithBody([]byte(body), ""))
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	b := NewBench(WithRequests(3), withURL, WithReport(r))
+	b.Exec(context.Background())
+
+	if h.totalRequests != 3 {
+		t.Fatalf("Expected 3 requests but got %d", h.totalRequests)
+	}
+
+	for _, req := range h.requests {
+		if req.body != body || req.headers["Content-Type"] != "application/json" || req.method != http.MethodPut {
+			t.Errorf("Unexpected request: %+v", req)
+		}
+	}
+}
+
+func TestExecMultipart(t *testing.T) {
+	file, err := ioutil.TempFile("", "gbench-upload")
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	defer os.Remove(file.Name())
+
+	content := strings.Repeat("gbench", 100000)
+	file.WriteString(content)
+	file.Close()
+
+	lock := &sync.Mutex{}
+	received := 0
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Errorf("Could not parse the multipart form: %v", err)
+			return
+		}
+
+		if r.FormValue("name") != "gbench" {
+			t.Errorf("Unexpected field value: %s", r.FormValue("name"))
+		}
+
+		upload, header, err := r.FormFile("upload")
+
+		if err != nil {
+			t.Errorf("Could not get the file: %v", err)
+			return
+		}
+
+		defer upload.Close()
+
+		uploaded, _ := ioutil.ReadAll(upload)
+
+		if string(uploaded) != content || header.Filename != "data.txt" || header.Header.Get("Content-Type") != "text/plain" {
+			t.Errorf("Unexpected file %s with content type %s", header.Filename, header.Header.Get("Content-Type"))
+		}
+
+		lock.Lock()
+		received++
+		lock.Unlock()
+	}))
+	defer ts.Close()
+
+	r := &report.Result{}
+	r.Init(1)
+
+	withURL, err := WithURLSettings(ts.URL, "POST", []string{}, []string{}, "", "",
+		WithPart(&Part{Name: "name", Value: "gbench"}),
+		WithPart(&Part{Name: "upload", File: file.Name(), FileName: "data.txt", ContentType: "text/plain"}))
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	b := NewBench(WithRequests(3), WithConcurrency(2), withURL, WithReport(r))
+	b.Exec(context.Background())
+
+	if received != 3 || r.SuccessfulRequests != 3 {
+		t.Errorf("Expected 3 successful uploads but got %d", received)
+	}
+}
+
+func TestExecTemplates(t *testing.T) {
+	h := newTestHTTP(http.StatusOK)
+	ts := httptest.NewServer(h)
+	defer ts.Close()
+
+	r := &report.Result{}
+	r.Init(2)
+
+	addr := ts.URL + "/users/{{seq}}"
+	withURL, err := WithURLSettings(addr, "PUT", []string{}, []string{"X-Worker: {{worker}}"}, "", "",
+		WithBody([]byte(`{"id": "{{uuid}}"}`), ""))
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	b := NewBench(WithRequests(6), WithConcurrency(2), withURL, WithReport(r))
+	b.Exec(context.Background())
+
+	paths, bodies := make(map[string]bool), make(map[string]bool)
+
+	for _, req := range h.requests {
+		paths[req.path] = true
+		bodies[req.body] = true
+
+		if req.headers["X-Worker"] != "1" && req.headers["X-Worker"] != "2" {
+			t.Errorf("Unexpected worker id: %s", req.headers["X-Worker"])
+		}
+	}
+
+	if len(paths) != 6 || !paths["/users/1"] || !paths["/users/6"] || len(bodies) != 6 {
+		t.Errorf("Expected distinct requests but got %v and %v", paths, bodies)
+	}
+
+	if len(r.URLs) != 1 || r.ResponseStatusCode[addr][http.StatusOK] != 6 {
+		t.Errorf("Expected the responses to be reported under the template but got %v", r.URLs)
+	}
+}
+
+func TestExecInvalidRequest(t *testing.T) {
+	h := newTestHTTP(http.StatusOK)
+	ts := httptest.NewServer(h)
+	defer ts.Close()
+
+	for _, withLoad := range []func(*Bench){WithConcurrency(2), WithRate(100)} {
+		r := &report.Result{}
+		r.Init(2)
+
+		b := NewBench(WithRequests(4), withLoad, WithURL(&URL{Addr: ts.URL, Method: "BAD METHOD"}), WithReport(r))
+
+		if err := b.Exec(context.Background()); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if h.totalRequests != 0 || r.TotalRequests != 4 || r.FailedResponse[ts.URL] != 4 || r.Errors[ts.URL][report.ErrorOther].Count != 4 {
+			t.Errorf("Expected the requests which can not be created to fail but got %+v", r)
+		}
+	}
+}
+
+func TestExecFeeder(t *testing.T) {
+	h := newTestHTTP(http.StatusOK)
+	ts := httptest.NewServer(h)
+	defer ts.Close()
+
+	dir, err := ioutil.TempDir("", "gbench-feeder")
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	f, err := NewFeeder(writeFeederFile(t, dir, "users.csv", "id,token\n1,a\n2,b\n3,c\n"), FeederOnce)
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	r := &report.Result{}
+	r.Init(2)
+
+	withURL, _ := WithURLSettings(ts.URL+"/users/{{feed id}}", "GET", []string{}, []string{"Authorization: Bearer {{feed token}}"}, "", "")
+
+	b := NewBench(WithDuration(time.Minute), WithConcurrency(2), withURL, WithFeeder(f), WithReport(r))
+
+	if err := b.Exec(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if r.StopReason != report.StopReasonFeeder || h.totalRequests != 3 {
+		t.Fatalf("Expected to stop after 3 requests because of the feeder but got %d requests and %s", h.totalRequests, r.StopReason)
+	}
+
+	tokens := map[string]string{"/users/1": "Bearer a", "/users/2": "Bearer b", "/users/3": "Bearer c"}
+
+	for _, req := range h.requests {
+		if tokens[req.path] != req.headers["Authorization"] {
+			t.Errorf("Unexpected token %s for %s", req.headers["Authorization"], req.path)
+		}
+	}
+
+	withURL, _ = WithURLSettings(ts.URL+"/users/{{feed name}}", "GET", []string{}, []string{}, "", "")
+
+	if err := NewBench(withURL, WithReport(r)).Exec(context.Background()); err == nil || err.Error() != "No feeder is set for {{feed name}}" {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	if err := NewBench(withURL, WithFeeder(f), WithReport(r)).Exec(context.Background()); err == nil || err.Error() != "Feeder has no column name" {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestExecDuration(t *testing.T) {
+	h := newTestHTTP(http.StatusOK)
+	ts := httptest.NewServer(h)
+	defer ts.Close()
+
+	r := &report.Result{}
+	r.Init(2)
+
+	withURL, _ := WithURLSettings(ts.URL, "GET", []string{}, []string{}, "", "")
+
+	b := NewBench(WithConcurrency(2), WithDuration(100*time.Millisecond), withURL, WithReport(r))
+
+	if b.Requests != 0 {
+		t.Fatalf("Expected no request limit but got %d", b.Requests)
+	}
+
+	if err := b.Exec(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if r.StopReason != report.StopReasonDuration {
+		t.Errorf("Expected %q as stop reason but got %q", report.StopReasonDuration, r.StopReason)
+	}
+
+	if r.TotalRequests == 0 || r.TotalRequests != h.totalRequests {
+		t.Errorf("Expected the same number of requests in the report and the server but got %d and %d", r.TotalRequests, h.totalRequests)
+	}
+
+	if r.TotalTime < 100*time.Millisecond {
+		t.Errorf("Expected the benchmark to run for at least 100ms but got %v", r.TotalTime)
+	}
+}
+
+func TestExecDurationWithRequests(t *testing.T) {
+	h := newTestHTTP(http.StatusOK)
+	ts := httptest.NewServer(h)
+	defer ts.Close()
+
+	r := &report.Result{}
+	r.Init(2)
+
+	withURL, _ := WithURLSettings(ts.URL, "GET", []string{}, []string{}, "", "")
+
+	b := NewBench(WithConcurrency(2), WithRequests(3), WithDuration(time.Minute), withURL, WithReport(r))
+	b.Exec(context.Background())
+
+	if r.StopReason != report.StopReasonRequests {
+		t.Errorf("Expected %q as stop reason but got %q", report.StopReasonRequests, r.StopReason)
+	}
+
+	if h.totalRequests != 3 {
+		t.Errorf("Expected 3 requests but got %d", h.totalRequests)
+	}
+}
+
+func TestExecCanceled(t *testing.T) {
+	h := newTestHTTP(http.StatusOK)
+	ts := httptest.NewServer(h)
+	defer ts.Close()
+
+	r := &report.Result{}
+	r.Init(1)
+
+	withURL, _ := WithURLSettings(ts.URL, "GET", []string{}, []string{}, "", "")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	b := NewBench(WithDuration(time.Minute), withURL, WithReport(r))
+
+	if err := b.Exec(ctx); err != context.Canceled {
+		t.Errorf("Expected to get %v but got %v", context.Canceled, err)
+	}
+
+	if r.StopReason != report.StopReasonCanceled {
+		t.Errorf("Expected %q as stop reason but got %q", report.StopReasonCanceled, r.StopReason)
+	}
+}
+
+func TestExecFeederInvalidRow(t *testing.T) {
+	h := newTestHTTP(http.StatusOK)
+	ts := httptest.NewServer(h)
+	defer ts.Close()
+
+	dir, err := ioutil.TempDir("", "gbench-feeder")
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	f, err := NewFeeder(writeFeederFile(t, dir, "queries.csv", "q\ngo\n%zz\nbench\n"), FeederOnce)
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	r := &report.Result{}
+	r.Init(2)
+
+	addr := ts.URL + "/search/{{feed q}}"
+	withURL, _ := WithURLSettings(addr, "GET", []string{}, []string{}, "", "")
+
+	b := NewBench(WithDuration(time.Minute), withURL, WithFeeder(f), WithReport(r))
+
+	if err := b.Exec(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if r.StopReason != report.StopReasonFeeder || h.totalRequests != 2 {
+		t.Fatalf("Expected to run through the feeder but got %d requests and %s", h.totalRequests, r.StopReason)
+	}
+
+	if r.TotalRequests != 3 || r.SuccessfulRequests != 2 || r.FailedResponse[addr] != 1 || r.Errors[addr][report.ErrorOther].Count != 1 {
+		t.Errorf("Expected the row with an invalid escape to fail but got %+v", r)
+	}
+}
+
+func TestExecRate(t *testing.T) {
+	h := newTestHTTP(http.StatusOK)
+	ts := httptest.NewServer(h)
+	defer ts.Close()
+
+	r := &report.Result{}
+	r.Init(1)
+
+	withURL, _ := WithURLSettings(ts.URL, "GET", []string{}, []string{}, "", "")
+
+	b := NewBench(WithRate(100), WithRequests(10), withURL, WithReport(r))
+	b.Exec(context.Background())
+
+	if h.totalRequests != 10 || r.TotalRequests != 10 {
+		t.Errorf("Expected 10 requests but got %d in the server and %d in the report", h.totalRequests, r.TotalRequests)
+	}
+
+	// The last request is scheduled 90ms after the first one.
+	if r.TotalTime < 90*time.Millisecond {
+		t.Errorf("Expected the requests to be sent at the given rate but finished in %v", r.TotalTime)
+	}
+
+	if r.StopReason != report.StopReasonRequests {
+		t.Errorf("Expected %q as stop reason but got %q", report.StopReasonRequests, r.StopReason)
+	}
+}
+
+func TestExecRateDropped(t *testing.T) {
+	release := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer ts.Close()
+
+	r := &report.Result{}
+	r.Init(1)
+
+	withURL, _ := WithURLSettings(ts.URL, "GET", []string{}, []string{}, "", "")
+
+	b := NewBench(WithRate(1000), WithRequests(5), WithMaxInFlight(1), withURL, WithReport(r))
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		close(release)
+	}()
+
+	b.Exec(context.Background())
+
+	if r.TotalRequests != 1 || r.DroppedRequests != 4 || r.DroppedRequest[ts.URL] != 4 {
+		t.Errorf("Expected 1 sent and 4 dropped requests but got %d and %d", r.TotalRequests, r.DroppedRequests)
+	}
+}
+
+func TestExecWorkerPool(t *testing.T) {
+	var lock sync.Mutex
+	received := 0
+	others := make(chan struct{})
+
+	// The first request is blocked until all the other requests are received,
+	// which only happens when workers do not wait for each other.
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		received++
+		n := received
+		lock.Unlock()
+
+		if n == 10 {
+			close(others)
+		}
+
+		if n == 1 {
+			select {
+			case <-others:
+			case <-time.After(time.Second):
+				w.WriteHeader(http.StatusGatewayTimeout)
+			}
+		}
+	}))
+	defer ts.Close()
+
+	r := &report.Result{}
+	r.Init(2)
+
+	withURL, _ := WithURLSettings(ts.URL, "GET", []string{}, []string{}, "", "")
+
+	b := NewBench(WithConcurrency(2), WithRequests(10), withURL, WithReport(r))
+	b.Exec(context.Background())
+
+	if r.TotalRequests != 10 || r.SuccessfulRequests != 10 {
+		t.Errorf("Expected 10 successful requests but got %d of %d", r.SuccessfulRequests, r.TotalRequests)
+	}
+}
+
+func TestExecPhaseTimes(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(20 * time.Millisecond)
+		w.Write([]byte("Test data"))
+	}))
+	defer ts.Close()
+
+	r := &report.Result{}
+	r.Init(1)
+
+	withURL, _ := WithURLSettings(ts.URL, "GET", []string{}, []string{}, "", "")
+
+	b := NewBench(WithRequests(2), withURL, WithReport(r))
+	b.Exec(context.Background())
+
+	phases, ok := r.PhaseTimes[ts.URL]
+
+	if !ok || r.PhaseTimesCount[ts.URL] != 2 {
+		t.Fatalf("Expected phase times of 2 requests but got %d", r.PhaseTimesCount[ts.URL])
+	}
+
+	if phases.Connect <= 0 {
+		t.Error("Expected the connect time to be recorded")
+	}
+
+	if phases.TimeToFirstByte < 40*time.Millisecond {
+		t.Errorf("Expected at least 40ms as time to first byte but got %v", phases.TimeToFirstByte)
+	}
+
+	if phases.TLSHandshake != 0 {
+		t.Errorf("Did not expect a TLS handshake but got %v", phases.TLSHandshake)
+	}
+}
+
+func TestExecConnections(t *testing.T) {
+	var opened int64
+
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(5 * time.Millisecond)
+	}))
+	ts.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			atomic.AddInt64(&opened, 1)
+		}
+	}
+	ts.Start()
+	defer ts.Close()
+
+	withURL, _ := WithURLSettings(ts.URL, "GET", []string{}, []string{}, "", "")
+
+	tests := []struct {
+		name                  string
+		configurations        []func(*Bench)
+		newConns, reusedConns int
+	}{
+		{"keep-alive", []func(*Bench){WithRequests(5)}, 1, 4},
+		{"no keep-alive", []func(*Bench){WithRequests(5), WithDisableKeepAlives()}, 5, 0},
+		{"max conns per host", []func(*Bench){WithConcurrency(4), WithRequests(8), WithMaxConnsPerHost(1)}, 1, 7},
+	}
+
+	for _, test := range tests {
+		atomic.StoreInt64(&opened, 0)
+
+		r := &report.Result{}
+		r.Init(1)
+
+		if err := NewBench(append(test.configurations, withURL, WithReport(r))...).Exec(context.Background()); err != nil {
+			t.Fatalf("Unexpected error for %s: %v", test.name, err)
+		}
+
+		if r.NewConnections[ts.URL] != test.newConns || r.ReusedConnections[ts.URL] != test.reusedConns {
+			t.Errorf("Expected %d new and %d reused connections for %s but got %d and %d", test.newConns, test.reusedConns, test.name, r.NewConnections[ts.URL], r.ReusedConnections[ts.URL])
+		}
+
+		if n := atomic.LoadInt64(&opened); n != int64(test.newConns) {
+			t.Errorf("Expected the server to accept %d connections for %s but got %d", test.newConns, test.name, n)
+		}
+	}
+}
+
+func TestExecExpectedInterval(t *testing.T) {
+	h := newTestHTTP(http.StatusOK)
+	ts := httptest.NewServer(h)
+	defer ts.Close()
+
+	r := &report.Result{}
+	r.Init(1)
+
+	withURL, _ := WithURLSettings(ts.URL, "GET", []string{}, []string{}, "", "")
+
+	b := NewBench(WithRequests(2), WithExpectedInterval(time.Hour), withURL, WithReport(r))
+	b.Exec(context.Background())
+
+	if r.ExpectedInterval != time.Hour || r.CorrectedHistograms[ts.URL].TotalCount != 2 {
+		t.Error("Expected corrected response times to be recorded")
+	}
+}
+
+func TestExecTimeSeries(t *testing.T) {
+	h := newTestHTTP(http.StatusOK)
+	ts := httptest.NewServer(h)
+	defer ts.Close()
+
+	r := &report.Result{}
+	r.Init(1)
+	r.SetTimeSeriesInterval(time.Hour)
+
+	withURL, _ := WithURLSettings(ts.URL, "GET", []string{}, []string{}, "", "")
+
+	b := NewBench(WithRequests(3), withURL, WithReport(r))
+	b.Exec(context.Background())
+
+	if len(r.TimeSeries[ts.URL]) != 1 {
+		t.Fatalf("Expected a single bucket but got %d", len(r.TimeSeries[ts.URL]))
+	}
+
+	bucket := r.TimeSeries[ts.URL][0]
+
+	if bucket.SentRequests != 3 || bucket.StatusClasses["2xx"] != 3 || bucket.ReceivedDataLength != 27 {
+		t.Errorf("Unexpected bucket: %+v", bucket)
+	}
+}
+
+func TestCurrentStage(t *testing.T) {
+	b := NewBench(
+		WithStage(&Stage{Name: "ramp", Duration: 10 * time.Second, From: 10, To: 110}),
+		WithStage(&Stage{Name: "hold", Duration: 5 * time.Second, From: 110, To: 110}),
+		WithStage(&Stage{Name: "down", Duration: 10 * time.Second, From: 10, To: 0}),
+	)
+
+	expected := []struct {
+		elapsed     time.Duration
+		stage       string
+		concurrency int
+	}{
+		{0, "ramp", 10},
+		{5 * time.Second, "ramp", 60},
+		{10 * time.Second, "hold", 110},
+		{14 * time.Second, "hold", 110},
+		{15 * time.Second, "down", 10},
+		{20 * time.Second, "down", 5},
+		{24 * time.Second, "down", 1},
+		{25 * time.Second, "", 0},
+	}
+
+	for _, e := range expected {
+		stage, concurrency := b.currentStage(e.elapsed)
+
+		if (stage == nil && e.stage != "") || (stage != nil && stage.Name != e.stage) || concurrency != e.concurrency {
+			t.Errorf("Expected %q with concurrency %d after %v but got %+v with %d", e.stage, e.concurrency, e.elapsed, stage, concurrency)
+		}
+	}
+}
+
+func TestExecStages(t *testing.T) {
+	h := newTestHTTP(http.StatusOK)
+	ts := httptest.NewServer(h)
+	defer ts.Close()
+
+	r := &report.Result{}
+	r.Init(1)
+
+	withURL, _ := WithURLSettings(ts.URL, "GET", []string{}, []string{}, "", "")
+
+	b := NewBench(
+		WithStage(&Stage{Name: "first", Duration: 150 * time.Millisecond, From: 1, To: 2}),
+		WithStage(&Stage{Name: "second", Duration: 150 * time.Millisecond, From: 3, To: 3}),
+		withURL,
+		WithReport(r),
+	)
+	b.Exec(context.Background())
+
+	if r.StopReason != report.StopReasonStages {
+		t.Errorf("Expected %q as stop reason but got %q", report.StopReasonStages, r.StopReason)
+	}
+
+	if len(r.StageResult) != 2 || r.StageResult[0].Name != "first" || r.StageResult[1].Name != "second" {
+		t.Fatalf("Unexpected stage results: %+v", r.StageResult)
+	}
+
+	total := 0
+
+	for _, stageResult := range r.StageResult {
+		if stageResult.TotalRequests == 0 || stageResult.TotalRequests != stageResult.SuccessfulRequests {
+			t.Errorf("Unexpected result for %s: %+v", stageResult.Name, stageResult)
+		}
+
+		total += stageResult.TotalRequests
+	}
+
+	if total != r.TotalRequests {
+		t.Errorf("Expected %d requests in stages but got %d", r.TotalRequests, total)
+	}
+}
+
 func checkRequest(t *testing.T, h *testHTTP, expected *testRequest) {
 	for _, request := range h.requests {
 		if request.cookie != expected.cookie {
@@ -292,3 +927,126 @@ func checkStatusCodes(t *testing.T, statusCodes, expectedStatusCodes map[string]
 		}
 	}
 }
+
+func TestExecTLS(t *testing.T) {
+	var peerCertified int32
+
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(r.TLS.PeerCertificates) > 0 {
+			atomic.StoreInt32(&peerCertified, 1)
+		}
+	}))
+	ts.TLS = &tls.Config{ClientAuth: tls.RequestClientCert}
+	ts.StartTLS()
+	defer ts.Close()
+
+	dir, err := ioutil.TempDir("", "gbench-tls")
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	caPath := filepath.Join(dir, "ca.pem")
+	pem.Encode(writeTestFile(t, caPath), &pem.Block{Type: "CERTIFICATE", Bytes: ts.Certificate().Raw})
+
+	certPath, keyPath := writeTestClientCert(t, dir)
+
+	withCA, err := WithCACert(caPath)
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	withClientCert, err := WithClientCert(certPath, keyPath)
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	withVersions, _ := WithTLSVersions("1.2", "1.2")
+
+	tests := []struct {
+		name          string
+		configs       []func(*Bench)
+		success       bool
+		peerCertified bool
+	}{
+		{"no TLS options", []func(*Bench){}, false, false},
+		{"insecure", []func(*Bench){WithInsecureSkipVerify()}, true, false},
+		{"CA", []func(*Bench){withCA}, true, false},
+		{"CA with server name", []func(*Bench){withCA, WithServerName("example.com")}, true, false},
+		{"CA with wrong server name", []func(*Bench){withCA, WithServerName("wrong.example")}, false, false},
+		{"client certificate", []func(*Bench){withCA, withClientCert, withVersions}, true, true},
+	}
+
+	for _, test := range tests {
+		atomic.StoreInt32(&peerCertified, 0)
+
+		r := &report.Result{}
+		r.Init(1)
+
+		withURL, _ := WithURLSettings(ts.URL, "GET", []string{}, []string{}, "", "")
+
+		if err := NewBench(append(test.configs, withURL, WithReport(r))...).Exec(context.Background()); err != nil {
+			t.Fatalf("Unexpected error for %s: %v", test.name, err)
+		}
+
+		if (r.SuccessfulRequests == 1) != test.success {
+			t.Errorf("Expected success to be %v for %s but got %d successful requests", test.success, test.name, r.SuccessfulRequests)
+		}
+
+		if test.success && (atomic.LoadInt32(&peerCertified) == 1) != test.peerCertified {
+			t.Errorf("Expected the client certificate to be sent to be %v for %s", test.peerCertified, test.name)
+		}
+	}
+}
+
+func writeTestFile(t *testing.T, path string) *os.File {
+	f, err := os.Create(path)
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	t.Cleanup(func() { f.Close() })
+
+	return f
+}
+
+// writeTestClientCert writes a self-signed client certificate and its key.
+func writeTestClientCert(t *testing.T, dir string) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "gbench"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+
+	cert, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	keyBytes, err := x509.MarshalECPrivateKey(key)
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	certPath, keyPath := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+
+	pem.Encode(writeTestFile(t, certPath), &pem.Block{Type: "CERTIFICATE", Bytes: cert})
+	pem.Encode(writeTestFile(t, keyPath), &pem.Block{Type: "EC PRIVATE KEY", Bytes: keyBytes})
+
+	return certPath, keyPath
+}
@@ -0,0 +1,183 @@
+package bench
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Feeder modes define how rows are picked for requests.
+const (
+	// FeederSequential picks the rows in order and starts over after the last
+	// one.
+	FeederSequential = "sequential"
+	// FeederRandom picks a random row for every request.
+	FeederRandom = "random"
+	// FeederOnce picks the rows in order and uses every row only once. The
+	// benchmark stops when all the rows are used.
+	FeederOnce = "once"
+)
+
+// Feeder feeds the values of the rows of a CSV or JSONL file to the request
+// templates (i.e. {{feed user_id}}). It is safe for concurrent use.
+type Feeder struct {
+	// Mode of picking the rows (Default is sequential).
+	Mode string
+	// Column names of the rows.
+	Columns []string
+
+	rows      []map[string]string
+	next      int64
+	exhausted chan struct{}
+	once      sync.Once
+}
+
+// NewFeeder creates a feeder using the rows of the given CSV or JSONL file.
+// The format is detected using the extension of the file. The first line of a
+// CSV file contains the column names.
+func NewFeeder(path, mode string) (*Feeder, error) {
+	if mode == "" {
+		mode = FeederSequential
+	}
+
+	if mode != FeederSequential && mode != FeederRandom && mode != FeederOnce {
+		return nil, fmt.Errorf("Wrong feeder mode: %s", mode)
+	}
+
+	file, err := os.Open(path)
+
+	if err != nil {
+		return nil, fmt.Errorf("Could not open the feeder file: %v", err)
+	}
+
+	defer file.Close()
+
+	f := &Feeder{Mode: mode, exhausted: make(chan struct{})}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		err = f.readCSV(file)
+	case ".jsonl", ".ndjson":
+		err = f.readJSONL(file)
+	default:
+		return nil, fmt.Errorf("Unsupported feeder file: %s (Only .csv and .jsonl are supported)", path)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(f.rows) == 0 {
+		return nil, fmt.Errorf("Feeder file %s has no rows", path)
+	}
+
+	return f, nil
+}
+
+func (f *Feeder) readCSV(r io.Reader) error {
+	records, err := csv.NewReader(r).ReadAll()
+
+	if err != nil {
+		return fmt.Errorf("Wrong CSV feeder file: %v", err)
+	}
+
+	if len(records) == 0 {
+		return nil
+	}
+
+	f.Columns = records[0]
+
+	for _, record := range records[1:] {
+		row := make(map[string]string, len(f.Columns))
+
+		for i, column := range f.Columns {
+			row[column] = record[i]
+		}
+
+		f.rows = append(f.rows, row)
+	}
+
+	return nil
+}
+
+func (f *Feeder) readJSONL(r io.Reader) error {
+	columns := make(map[string]bool)
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+
+		values := make(map[string]json.RawMessage)
+
+		if err := json.Unmarshal(scanner.Bytes(), &values); err != nil {
+			return fmt.Errorf("Wrong JSONL feeder file at line %d: %v", line, err)
+		}
+
+		row := make(map[string]string, len(values))
+
+		for column, value := range values {
+			// Strings are unquoted while other values are kept as JSON.
+			var s string
+
+			if err := json.Unmarshal(value, &s); err != nil {
+				s = string(value)
+			}
+
+			row[column] = s
+
+			if !columns[column] {
+				columns[column] = true
+				f.Columns = append(f.Columns, column)
+			}
+		}
+
+		f.rows = append(f.rows, row)
+	}
+
+	return scanner.Err()
+}
+
+// row returns the row to use for the next request. It returns false when all
+// the rows of a once feeder are used.
+func (f *Feeder) row() (map[string]string, bool) {
+	switch f.Mode {
+	case FeederRandom:
+		return f.rows[rand.Intn(len(f.rows))], true
+	case FeederOnce:
+		n := atomic.AddInt64(&f.next, 1) - 1
+
+		if n >= int64(len(f.rows)) {
+			f.once.Do(func() { close(f.exhausted) })
+			return nil, false
+		}
+
+		return f.rows[n], true
+	}
+
+	n := atomic.AddInt64(&f.next, 1) - 1
+
+	return f.rows[n%int64(len(f.rows))], true
+}
+
+// hasColumn checks whether the rows of the feeder have the given column.
+func (f *Feeder) hasColumn(column string) bool {
+	for _, c := range f.Columns {
+		if c == column {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,156 @@
+package bench
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func writeFeederFile(t *testing.T, dir, name, content string) string {
+	path := filepath.Join(dir, name)
+
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	return path
+}
+
+func TestFeeder(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gbench-feeder")
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	csvPath := writeFeederFile(t, dir, "users.csv", "id,token\n1,a\n2,b\n3,c\n")
+	jsonlPath := writeFeederFile(t, dir, "users.jsonl", "{\"id\": 1, \"token\": \"a\"}\n\n{\"id\": 2, \"token\": \"b\", \"admin\": true}\n")
+
+	f, err := NewFeeder(csvPath, "")
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if f.Mode != FeederSequential || len(f.Columns) != 2 || !f.hasColumn("token") || f.hasColumn("foo") {
+		t.Errorf("Unexpected feeder: %+v", f)
+	}
+
+	for _, expected := range []string{"1", "2", "3", "1"} {
+		if row, ok := f.row(); !ok || row["id"] != expected {
+			t.Errorf("Expected row %s but got %v", expected, row)
+		}
+	}
+
+	f, err = NewFeeder(jsonlPath, FeederOnce)
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(f.Columns) != 3 {
+		t.Errorf("Expected 3 columns but got %v", f.Columns)
+	}
+
+	if row, ok := f.row(); !ok || row["id"] != "1" || row["token"] != "a" {
+		t.Errorf("Unexpected first row: %v", row)
+	}
+
+	if row, ok := f.row(); !ok || row["admin"] != "true" {
+		t.Errorf("Unexpected second row: %v", row)
+	}
+
+	if _, ok := f.row(); ok {
+		t.Error("Expected the once feeder to be exhausted")
+	}
+
+	select {
+	case <-f.exhausted:
+	default:
+		t.Error("Expected the exhausted channel to be closed")
+	}
+
+	f, _ = NewFeeder(csvPath, FeederRandom)
+
+	for i := 0; i < 10; i++ {
+		if row, ok := f.row(); !ok || row["id"] == "" {
+			t.Errorf("Unexpected random row: %v", row)
+		}
+	}
+
+	wrongFeeders := map[string]string{
+		csvPath + "|fast":                                         "Wrong feeder mode: fast",
+		filepath.Join(dir, "missing.csv"):                         "Could not open the feeder file",
+		writeFeederFile(t, dir, "users.txt", "id\n1\n"):           "Unsupported feeder file",
+		writeFeederFile(t, dir, "empty.csv", "id\n"):              "has no rows",
+		writeFeederFile(t, dir, "wrong.csv", "id,token\n1\n"):     "Wrong CSV feeder file",
+		writeFeederFile(t, dir, "wrong.jsonl", "{\"id\": 1}\n[]"): "Wrong JSONL feeder file at line 2",
+	}
+
+	for feeder, expected := range wrongFeeders {
+		path, mode := feeder, ""
+
+		if parts := strings.Split(feeder, "|"); len(parts) == 2 {
+			path, mode = parts[0], parts[1]
+		}
+
+		if _, err := NewFeeder(path, mode); err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("Expected to get %q for %s but got %v", expected, feeder, err)
+		}
+	}
+}
+
+func TestFeederConcurrency(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gbench-feeder")
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	content := "id\n"
+
+	for i := 0; i < 100; i++ {
+		content += "row\n"
+	}
+
+	f, err := NewFeeder(writeFeederFile(t, dir, "rows.csv", content), FeederOnce)
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	lock := &sync.Mutex{}
+	used := 0
+	wg := &sync.WaitGroup{}
+
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for {
+				if _, ok := f.row(); !ok {
+					return
+				}
+
+				lock.Lock()
+				used++
+				lock.Unlock()
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if used != 100 {
+		t.Errorf("Expected every row to be used once but got %d rows", used)
+	}
+}
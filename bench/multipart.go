@@ -0,0 +1,89 @@
+package bench
+
+import (
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+// newMultipartRequest creates a request whose multipart body is written while
+// it is being sent. Files are copied in chunks so they are never fully kept in
+// memory.
+func newMultipartRequest(u *URL) (*http.Request, error) {
+	pr, pw := io.Pipe()
+	writer := multipart.NewWriter(pw)
+
+	req, err := http.NewRequest(u.Method, u.Addr, pr)
+
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Add("Content-Type", writer.FormDataContentType())
+
+	go func() {
+		pw.CloseWithError(writeParts(writer, u.Parts))
+	}()
+
+	return req, nil
+}
+
+func writeParts(writer *multipart.Writer, parts []*Part) error {
+	for _, part := range parts {
+		if part.File == "" {
+			if err := writer.WriteField(part.Name, part.Value); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		if err := writeFilePart(writer, part); err != nil {
+			return err
+		}
+	}
+
+	return writer.Close()
+}
+
+func writeFilePart(writer *multipart.Writer, part *Part) error {
+	file, err := os.Open(part.File)
+
+	if err != nil {
+		return err
+	}
+
+	defer file.Close()
+
+	fileName, contentType := part.FileName, part.ContentType
+
+	if fileName == "" {
+		fileName = filepath.Base(part.File)
+	}
+
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`,
+		quoteEscaper.Replace(part.Name), quoteEscaper.Replace(fileName)))
+	header.Set("Content-Type", contentType)
+
+	w, err := writer.CreatePart(header)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(w, file)
+
+	return err
+}
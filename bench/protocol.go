@@ -0,0 +1,82 @@
+package bench
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+)
+
+// Protocols of the requests.
+const (
+	// ProtocolHTTP1 sends the requests over HTTP/1.1 only.
+	ProtocolHTTP1 = "http1"
+	// ProtocolHTTP2 sends the requests over HTTP/2 using TLS.
+	ProtocolHTTP2 = "http2"
+	// ProtocolH2C sends the requests over HTTP/2 without TLS with prior
+	// knowledge of the server support (h2c).
+	ProtocolH2C = "h2c"
+)
+
+// checkProtocol checks that the number of streams per connection is only set
+// for HTTP/2.
+func (b *Bench) checkProtocol() error {
+	if b.StreamsPerConnection > 0 && b.Protocol == ProtocolHTTP1 {
+		return errors.New("Streams per connection can only be used with http2 or h2c")
+	}
+
+	return nil
+}
+
+// setProtocols sets the protocols which a transport can use. When the number
+// of streams per connection is set, requests exceeding the limit of the
+// server wait for a stream instead of opening new connections.
+func (b *Bench) setProtocols(tr *http.Transport) {
+	tr.Protocols = new(http.Protocols)
+
+	switch b.Protocol {
+	case ProtocolHTTP2:
+		tr.Protocols.SetHTTP2(true)
+	case ProtocolH2C:
+		tr.Protocols.SetUnencryptedHTTP2(true)
+	default:
+		tr.Protocols.SetHTTP1(true)
+		return
+	}
+
+	if b.StreamsPerConnection > 0 {
+		tr.HTTP2 = &http.HTTP2Config{StrictMaxConcurrentRequests: true}
+	}
+}
+
+// clientPool gives the workers their HTTP clients. All the workers share one
+// client unless the number of streams per connection is set. Then every group
+// of StreamsPerConnection slots shares a client, and so a connection, of its
+// own.
+type clientPool struct {
+	b       *Bench
+	lock    sync.Mutex
+	clients []*http.Client
+}
+
+func newClientPool(b *Bench) *clientPool {
+	return &clientPool{b: b, clients: []*http.Client{b.getClient()}}
+}
+
+// get returns the client of a slot. Slots are numbered from zero and each
+// slot sends one request at a time.
+func (c *clientPool) get(slot int) *http.Client {
+	if c.b.StreamsPerConnection <= 0 {
+		return c.clients[0]
+	}
+
+	group := slot / c.b.StreamsPerConnection
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for len(c.clients) <= group {
+		c.clients = append(c.clients, c.b.getClient())
+	}
+
+	return c.clients[group]
+}
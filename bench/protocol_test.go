@@ -0,0 +1,94 @@
+package bench
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sasanrose/gbench/report"
+)
+
+func TestExecProtocols(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+	tlsServer := httptest.NewUnstartedServer(handler)
+	tlsServer.EnableHTTP2 = true
+	tlsServer.StartTLS()
+	defer tlsServer.Close()
+
+	h2cServer := httptest.NewUnstartedServer(handler)
+	h2cServer.Config.Protocols = new(http.Protocols)
+	h2cServer.Config.Protocols.SetHTTP1(true)
+	h2cServer.Config.Protocols.SetUnencryptedHTTP2(true)
+	h2cServer.Start()
+	defer h2cServer.Close()
+
+	tests := []struct {
+		protocol, url, expected string
+	}{
+		{ProtocolHTTP1, tlsServer.URL, "HTTP/1.1"},
+		{ProtocolHTTP2, tlsServer.URL, "HTTP/2.0"},
+		{ProtocolHTTP1, h2cServer.URL, "HTTP/1.1"},
+		{ProtocolH2C, h2cServer.URL, "HTTP/2.0"},
+	}
+
+	for _, test := range tests {
+		r := &report.Result{}
+		r.Init(2)
+
+		protocol, _ := WithProtocol(test.protocol)
+		withURL, _ := WithURLSettings(test.url, "GET", []string{}, []string{}, "", "")
+
+		b := NewBench(WithConcurrency(2), WithRequests(4), withURL, protocol, WithInsecureSkipVerify(), WithReport(r))
+
+		if err := b.Exec(context.Background()); err != nil {
+			t.Fatalf("Unexpected error for %s: %v", test.protocol, err)
+		}
+
+		if protocols := r.Protocols[test.url]; len(protocols) != 1 || protocols[test.expected] != 4 {
+			t.Errorf("Expected 4 responses over %s for %s but got %v", test.expected, test.protocol, protocols)
+		}
+	}
+}
+
+func TestExecStreamsPerConnection(t *testing.T) {
+	lock := &sync.Mutex{}
+	connections := make(map[string]int)
+
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		connections[r.RemoteAddr]++
+		lock.Unlock()
+
+		time.Sleep(10 * time.Millisecond)
+	}))
+	ts.Config.Protocols = new(http.Protocols)
+	ts.Config.Protocols.SetUnencryptedHTTP2(true)
+	ts.Start()
+	defer ts.Close()
+
+	r := &report.Result{}
+	r.Init(4)
+
+	protocol, _ := WithProtocol(ProtocolH2C)
+	withURL, _ := WithURLSettings(ts.URL, "GET", []string{}, []string{}, "", "")
+
+	b := NewBench(WithConcurrency(4), WithRequests(40), withURL, protocol, WithStreamsPerConnection(2), WithReport(r))
+
+	if err := b.Exec(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(connections) != 2 || r.Protocols[ts.URL]["HTTP/2.0"] != 40 {
+		t.Errorf("Expected 40 responses over 2 connections but got %v", connections)
+	}
+
+	b = NewBench(withURL, WithStreamsPerConnection(2))
+
+	if err := b.Exec(context.Background()); err == nil || err.Error() != "Streams per connection can only be used with http2 or h2c" {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,33 @@
+package bench
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// checkRedirect is the redirect policy of the clients. When redirects are
+// disabled the redirect response itself is returned.
+func (b *Bench) checkRedirect(req *http.Request, via []*http.Request) error {
+	if b.DisableRedirects {
+		return http.ErrUseLastResponse
+	}
+
+	// via contains the original request as well as the followed redirects.
+	if len(via) > b.MaxRedirects {
+		return fmt.Errorf("Stopped after %d redirects", b.MaxRedirects)
+	}
+
+	return nil
+}
+
+// countRedirects returns the number of redirects followed to receive a
+// response.
+func countRedirects(resp *http.Response) int {
+	redirects := 0
+
+	for req := resp.Request; req != nil && req.Response != nil; req = req.Response.Request {
+		redirects++
+	}
+
+	return redirects
+}
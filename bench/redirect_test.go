@@ -0,0 +1,59 @@
+package bench
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sasanrose/gbench/report"
+)
+
+func TestExecRedirects(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.Handle("/a", http.RedirectHandler("/b", http.StatusFound))
+	mux.Handle("/b", http.RedirectHandler("/c", http.StatusMovedPermanently))
+	mux.HandleFunc("/c", func(w http.ResponseWriter, r *http.Request) {})
+
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	withURL, _ := WithURLSettings(ts.URL+"/a", "GET", []string{}, []string{}, "", "")
+
+	r := &report.Result{}
+	r.Init(1)
+
+	if err := NewBench(WithRequests(3), withURL, WithReport(r)).Exec(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if r.ResponseStatusCode[ts.URL+"/a"][http.StatusOK] != 3 || r.Redirects[ts.URL+"/a"] != 6 {
+		t.Errorf("Expected 3 responses after 6 redirects but got %v and %d", r.ResponseStatusCode[ts.URL+"/a"], r.Redirects[ts.URL+"/a"])
+	}
+
+	if finalURLs := r.FinalURLs[ts.URL+"/a"]; len(finalURLs) != 1 || finalURLs[ts.URL+"/c"] != 3 {
+		t.Errorf("Unexpected final URLs: %v", finalURLs)
+	}
+
+	r = &report.Result{}
+	r.Init(1)
+
+	if err := NewBench(WithRequests(3), withURL, WithDisableRedirects(), WithReport(r)).Exec(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if r.FailedResponseStatusCode[ts.URL+"/a"][http.StatusFound] != 3 || len(r.Redirects) != 0 || len(r.FinalURLs) != 0 {
+		t.Errorf("Expected 3 redirect responses but got %v", r.FailedResponseStatusCode[ts.URL+"/a"])
+	}
+
+	r = &report.Result{}
+	r.Init(1)
+
+	if err := NewBench(WithRequests(3), withURL, WithMaxRedirects(1), WithReport(r)).Exec(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if r.FailedResponse[ts.URL+"/a"] != 3 || len(r.ResponseStatusCode[ts.URL+"/a"]) != 0 {
+		t.Errorf("Expected 3 failed requests after exceeding the redirects but got %d", r.FailedResponse[ts.URL+"/a"])
+	}
+}
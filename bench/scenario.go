@@ -0,0 +1,272 @@
+package bench
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Extraction types define where the value of a scenario variable is pulled
+// from.
+const (
+	// ExtractJSON extracts a value of a JSON body using a path (i.e.
+	// data.items.0.id or $.data.items[0].id).
+	ExtractJSON = "json"
+	// ExtractRegex extracts the first group (or the whole match without a
+	// group) of a regular expression from the body.
+	ExtractRegex = "regex"
+	// ExtractHeader extracts the value of a response header.
+	ExtractHeader = "header"
+	// ExtractCookie extracts the value of a cookie set by the response.
+	ExtractCookie = "cookie"
+)
+
+// Extraction pulls a value from the response of a step into a scenario
+// variable.
+type Extraction struct {
+	// Name of the variable (i.e. token for {{var token}}).
+	Variable string
+	// Type of the extraction and its expression (i.e. a JSON path, a regular
+	// expression, a header name or a cookie name).
+	Type, Expression string
+
+	regexp *regexp.Regexp
+}
+
+// NewExtraction creates an extraction of the given type.
+func NewExtraction(variable, extractionType, expression string) (*Extraction, error) {
+	if variable == "" {
+		return nil, errors.New("Extraction variable is empty")
+	}
+
+	if expression == "" {
+		return nil, fmt.Errorf("Extraction expression of %s is empty", variable)
+	}
+
+	e := &Extraction{Variable: variable, Type: extractionType, Expression: expression}
+
+	switch extractionType {
+	case ExtractJSON, ExtractHeader, ExtractCookie:
+	case ExtractRegex:
+		re, err := regexp.Compile(expression)
+
+		if err != nil {
+			return nil, fmt.Errorf("Wrong regular expression of %s: %v", variable, err)
+		}
+
+		e.regexp = re
+	default:
+		return nil, fmt.Errorf("Wrong extraction type of %s: %s", variable, extractionType)
+	}
+
+	return e, nil
+}
+
+// extract returns the value of the extraction from a response.
+func (e *Extraction) extract(resp *response) (string, error) {
+	switch e.Type {
+	case ExtractJSON:
+		return jsonPathString(resp.body, e.Expression)
+	case ExtractRegex:
+		match := e.regexp.FindSubmatch(resp.body)
+
+		if match == nil {
+			return "", fmt.Errorf("No match for %s", e.Expression)
+		}
+
+		if len(match) > 1 {
+			return string(match[1]), nil
+		}
+
+		return string(match[0]), nil
+	case ExtractHeader:
+		if values, ok := resp.header[http.CanonicalHeaderKey(e.Expression)]; ok && len(values) > 0 {
+			return values[0], nil
+		}
+
+		return "", fmt.Errorf("No header %s", e.Expression)
+	case ExtractCookie:
+		for _, cookie := range resp.cookies {
+			if cookie.Name == e.Expression {
+				return cookie.Value, nil
+			}
+		}
+
+		return "", fmt.Errorf("No cookie %s", e.Expression)
+	}
+
+	return "", fmt.Errorf("Wrong extraction type: %s", e.Type)
+}
+
+// jsonPathValue returns the value of a path in a JSON document. Keys and array
+// indexes are separated by dots (i.e. data.items.0.id). A leading $ and
+// bracketed indexes (i.e. $.data.items[0].id) are accepted as well.
+func jsonPathValue(document []byte, path string) (interface{}, error) {
+	var value interface{}
+
+	if err := json.Unmarshal(document, &value); err != nil {
+		return nil, fmt.Errorf("Body is not a valid JSON: %v", err)
+	}
+
+	path = strings.NewReplacer("[", ".", "]", "").Replace(path)
+	path = strings.TrimPrefix(strings.TrimPrefix(path, "$"), ".")
+
+	if path == "" {
+		return value, nil
+	}
+
+	for _, key := range strings.Split(path, ".") {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			child, ok := v[key]
+
+			if !ok {
+				return nil, fmt.Errorf("No key %s in path %s", key, path)
+			}
+
+			value = child
+		case []interface{}:
+			index, err := strconv.Atoi(key)
+
+			if err != nil || index < 0 || index >= len(v) {
+				return nil, fmt.Errorf("Wrong index %s in path %s", key, path)
+			}
+
+			value = v[index]
+		default:
+			return nil, fmt.Errorf("No key %s in path %s", key, path)
+		}
+	}
+
+	return value, nil
+}
+
+// jsonPathString returns the value of a path in a JSON document as a string.
+// Strings are unquoted while other values are kept as JSON.
+func jsonPathString(document []byte, path string) (string, error) {
+	value, err := jsonPathValue(document, path)
+
+	if err != nil {
+		return "", err
+	}
+
+	if s, ok := value.(string); ok {
+		return s, nil
+	}
+
+	encoded, err := json.Marshal(value)
+
+	return string(encoded), err
+}
+
+// stepURL returns the name which the requests of a step are reported under.
+func stepURL(s *Scenario, step *Step) string {
+	return s.Name + " > " + step.Name
+}
+
+// runScenario runs the steps of a scenario once for a virtual user. The steps
+// share the row of the feeder and the extracted variables. The iteration stops
+// at the first step which fails or whose values can not be extracted. It
+// returns false when all the rows of a once feeder are used.
+func (b *Bench) runScenario(ctx context.Context, client *http.Client, s *Scenario, worker int, stage string) bool {
+	tctx, ok := b.newTemplateContext(worker)
+
+	if !ok {
+		return false
+	}
+
+	tctx.vars = make(map[string]string)
+	start := time.Now()
+
+	for _, step := range s.Steps {
+		tctx.seq, tctx.now = atomic.AddInt64(&b.seq, 1), time.Now()
+
+		reqURL := stepURL(s, step)
+		req, err := b.renderRequest(step.URL, tctx)
+
+		var resp *response
+
+		if err != nil {
+			b.addRequestError(reqURL, err, stage)
+		} else {
+			resp = b.runBench(client, req.WithContext(ctx), reqURL, step.URL.Assertions, stage)
+		}
+
+		if resp != nil && !resp.failed && b.extractVariables(reqURL, step, resp, tctx.vars) {
+			continue
+		}
+
+		// Iterations interrupted by the end of the benchmark are not counted.
+		if ctx.Err() == nil {
+			b.Report.AddScenarioResult(s.Name, time.Since(start), reqURL)
+		}
+
+		return true
+	}
+
+	b.Report.AddScenarioResult(s.Name, time.Since(start), "")
+
+	return true
+}
+
+func (b *Bench) extractVariables(reqURL string, step *Step, resp *response, vars map[string]string) bool {
+	for _, extraction := range step.Extractions {
+		value, err := extraction.extract(resp)
+
+		if err != nil {
+			b.printOutputMessage(fmt.Sprintf("Could not extract %s for %s: %v\n", extraction.Variable, reqURL, err))
+			return false
+		}
+
+		vars[extraction.Variable] = value
+	}
+
+	return true
+}
+
+// checkScenarios checks that the variables used by the steps of the scenarios
+// are extracted by their previous steps.
+func (b *Bench) checkScenarios() error {
+	if len(b.Scenarios) > 0 && b.Rate > 0 {
+		return errors.New("Scenarios can not be used with a rate")
+	}
+
+	for _, u := range b.URLs {
+		if vars := u.templates.variables(); len(vars) > 0 {
+			return fmt.Errorf("{{var %s}} can only be used in the steps of a scenario", vars[0])
+		}
+	}
+
+	for _, s := range b.Scenarios {
+		if len(s.Steps) == 0 {
+			return fmt.Errorf("Scenario %s has no step", s.Name)
+		}
+
+		extracted := make(map[string]bool)
+
+		for _, step := range s.Steps {
+			if step.URL == nil {
+				return fmt.Errorf("Step %s has no URL", stepURL(s, step))
+			}
+
+			for _, variable := range step.URL.templates.variables() {
+				if !extracted[variable] {
+					return fmt.Errorf("Step %s uses {{var %s}} before it is extracted", stepURL(s, step), variable)
+				}
+			}
+
+			for _, extraction := range step.Extractions {
+				extracted[extraction.Variable] = true
+			}
+		}
+	}
+
+	return nil
+}
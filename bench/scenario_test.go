@@ -0,0 +1,201 @@
+package bench
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/sasanrose/gbench/report"
+)
+
+func TestJSONPathValue(t *testing.T) {
+	document := []byte(`{"data": {"token": "abc", "items": [{"id": 7}, {"id": 8}], "ok": true}}`)
+
+	paths := map[string]interface{}{
+		"data.token":         "abc",
+		"$.data.token":       "abc",
+		"data.items.1.id":    float64(8),
+		"$.data.items[0].id": float64(7),
+		"data.ok":            true,
+	}
+
+	for path, expected := range paths {
+		value, err := jsonPathValue(document, path)
+
+		if err != nil || value != expected {
+			t.Errorf("Expected %v for %s but got %v (%v)", expected, path, value, err)
+		}
+	}
+
+	for _, path := range []string{"data.missing", "data.items.2.id", "data.items.x", "data.token.length"} {
+		if _, err := jsonPathValue(document, path); err == nil {
+			t.Errorf("Expected an error for %s", path)
+		}
+	}
+
+	if _, err := jsonPathValue([]byte("not json"), "data"); err == nil {
+		t.Error("Expected an error for an invalid JSON body")
+	}
+}
+
+func TestExtraction(t *testing.T) {
+	resp := &response{
+		header:  http.Header{"X-Request-Id": []string{"42"}},
+		cookies: []*http.Cookie{{Name: "session", Value: "s3cr3t"}},
+		body:    []byte(`{"user": {"id": 5, "roles": ["admin"]}, "csrf": "token-123"}`),
+	}
+
+	tests := []struct {
+		extractionType, expression, expected string
+	}{
+		{ExtractJSON, "user.id", "5"},
+		{ExtractJSON, "user.roles", `["admin"]`},
+		{ExtractRegex, `"csrf": "([^"]+)"`, "token-123"},
+		{ExtractRegex, `token-\d+`, "token-123"},
+		{ExtractHeader, "x-request-id", "42"},
+		{ExtractCookie, "session", "s3cr3t"},
+	}
+
+	for _, test := range tests {
+		e, err := NewExtraction("value", test.extractionType, test.expression)
+
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if value, err := e.extract(resp); err != nil || value != test.expected {
+			t.Errorf("Expected %s for %s %s but got %s (%v)", test.expected, test.extractionType, test.expression, value, err)
+		}
+	}
+
+	for _, extraction := range [][]string{{"value", ExtractHeader, "X-Missing"}, {"value", ExtractCookie, "missing"}, {"value", ExtractRegex, "missing"}} {
+		e, _ := NewExtraction(extraction[0], extraction[1], extraction[2])
+
+		if _, err := e.extract(resp); err == nil {
+			t.Errorf("Expected an error for %v", extraction)
+		}
+	}
+
+	wrongExtractions := map[string][]string{
+		"Extraction variable is empty":                                                    {"", ExtractJSON, "a"},
+		"Extraction expression of token is empty":                                         {"token", ExtractJSON, ""},
+		"Wrong extraction type of token: xpath":                                           {"token", "xpath", "a"},
+		"Wrong regular expression of token: error parsing regexp: missing closing ): `(`": {"token", ExtractRegex, "("},
+	}
+
+	for expected, extraction := range wrongExtractions {
+		if _, err := NewExtraction(extraction[0], extraction[1], extraction[2]); err == nil || err.Error() != expected {
+			t.Errorf("Expected %q but got %v", expected, err)
+		}
+	}
+}
+
+func TestExecScenario(t *testing.T) {
+	lock := &sync.Mutex{}
+	tokens := make(map[string]int)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/login", func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "s-" + r.URL.Query().Get("user")})
+		fmt.Fprintf(w, `{"data": {"token": "t-%s"}}`, r.URL.Query().Get("user"))
+	})
+	mux.HandleFunc("/orders", func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		defer lock.Unlock()
+
+		tokens[r.Header.Get("Authorization")+" "+r.Header.Get("X-Session")]++
+		w.WriteHeader(http.StatusCreated)
+	})
+	mux.HandleFunc("/fail", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	login, _ := NewURL(ts.URL+"/login?user={{worker}}", "GET", []string{}, []string{}, "", "")
+	orders, _ := NewURL(ts.URL+"/orders", "POST", []string{}, []string{"Authorization: Bearer {{var token}}", "X-Session: {{var session}}"}, "", "")
+	fail, _ := NewURL(ts.URL+"/fail", "GET", []string{}, []string{}, "", "")
+
+	token, _ := NewExtraction("token", ExtractJSON, "data.token")
+	session, _ := NewExtraction("session", ExtractCookie, "session")
+
+	checkout := &Scenario{Name: "checkout", Steps: []*Step{
+		{Name: "login", URL: login, Extractions: []*Extraction{token, session}},
+		{Name: "orders", URL: orders},
+	}}
+
+	broken := &Scenario{Steps: []*Step{{URL: login}, {URL: fail}, {URL: login}}}
+
+	r := &report.Result{}
+	r.Init(2)
+
+	b := NewBench(WithConcurrency(2), WithRequests(6), WithScenario(checkout), WithScenario(broken), WithReport(r))
+
+	if err := b.Exec(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if r.StopReason != report.StopReasonRequests {
+		t.Errorf("Unexpected stop reason: %s", r.StopReason)
+	}
+
+	if len(tokens) > 2 || tokens["Bearer t-1 s-1"]+tokens["Bearer t-2 s-2"] != 6 {
+		t.Errorf("Unexpected extracted values: %v", tokens)
+	}
+
+	result := r.ScenarioResult["checkout"]
+
+	if result == nil || result.Iterations != 6 || result.SuccessfulIterations != 6 || result.TimeHistogram.TotalCount != 6 {
+		t.Fatalf("Unexpected result for checkout: %+v", result)
+	}
+
+	if len(result.Steps) != 2 || result.Steps[0] != "checkout > login" || result.Steps[1] != "checkout > orders" {
+		t.Errorf("Unexpected steps: %v", result.Steps)
+	}
+
+	if r.ResponseStatusCode["checkout > orders"][http.StatusCreated] != 6 {
+		t.Errorf("Unexpected status codes for the orders step: %v", r.ResponseStatusCode["checkout > orders"])
+	}
+
+	result = r.ScenarioResult["scenario 2"]
+
+	if result == nil || result.FailedIterations != 6 || result.FailedSteps["scenario 2 > step 2"] != 6 {
+		t.Fatalf("Unexpected result for the broken scenario: %+v", result)
+	}
+
+	if r.TotalRequests != 6*2+6*2 {
+		t.Errorf("Expected the broken scenario to stop at the failed step but got %d requests", r.TotalRequests)
+	}
+}
+
+func TestCheckScenarios(t *testing.T) {
+	orders, _ := NewURL("http://localhost/orders", "GET", []string{}, []string{"Authorization: Bearer {{var token}}"}, "", "")
+	token, _ := NewExtraction("token", ExtractJSON, "token")
+
+	benches := map[string]*Bench{
+		"Scenarios can not be used with a rate":                     NewBench(WithRate(10), WithScenario(&Scenario{Steps: []*Step{{URL: orders}}})),
+		"{{var token}} can only be used in the steps of a scenario": NewBench(WithURL(orders)),
+		"Scenario scenario 1 has no step":                           NewBench(WithScenario(&Scenario{})),
+		"Step scenario 1 > step 1 has no URL":                       NewBench(WithScenario(&Scenario{Steps: []*Step{{}}})),
+		"Step scenario 1 > step 1 uses {{var token}} before it is extracted": NewBench(WithScenario(&Scenario{Steps: []*Step{
+			{URL: orders, Extractions: []*Extraction{token}},
+		}})),
+	}
+
+	for expected, b := range benches {
+		if err := b.checkScenarios(); err == nil || err.Error() != expected {
+			t.Errorf("Expected %q but got %v", expected, err)
+		}
+	}
+
+	login, _ := NewURL("http://localhost/login", "GET", []string{}, []string{}, "", "")
+	b := NewBench(WithScenario(&Scenario{Steps: []*Step{{URL: login, Extractions: []*Extraction{token}}, {URL: orders}}}))
+
+	if err := b.checkScenarios(); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,481 @@
+package bench
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const randomStringLetters = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+// templateExpression matches the expressions of a template. An expression is
+// either a function call or a quoted string which is written as is, so {{ can
+// be escaped as {{"{{"}}.
+var templateExpression = regexp.MustCompile(`{{\s*("(?:[^"\\]|\\.)*"|.*?)\s*}}`)
+
+// templateContext contains the values of a single request which are shared by
+// all of its templates.
+type templateContext struct {
+	seq    int64
+	worker int
+	now    time.Time
+	// Row of the feeder for the request.
+	row map[string]string
+	// Variables extracted by the previous steps of a scenario.
+	vars map[string]string
+}
+
+// templatePart writes a literal or the result of an expression of a template.
+type templatePart func(buf *bytes.Buffer, ctx *templateContext)
+
+// requestTemplate is a string with expressions which are evaluated for every
+// request (i.e. /users/{{randInt 1 100}}).
+type requestTemplate struct {
+	parts []templatePart
+	// Feeder columns and scenario variables used by the template.
+	columns, vars []string
+}
+
+// templateFunctions creates the parts of the supported expressions given
+// their arguments.
+var templateFunctions = map[string]func(args []string) (templatePart, error){
+	"randInt": func(args []string) (templatePart, error) {
+		if len(args) != 2 {
+			return nil, fmt.Errorf("randInt expects min and max: %v", args)
+		}
+
+		min, minErr := strconv.ParseInt(args[0], 10, 64)
+		max, maxErr := strconv.ParseInt(args[1], 10, 64)
+
+		if minErr != nil || maxErr != nil || min > max {
+			return nil, fmt.Errorf("Wrong randInt range: %v", args)
+		}
+
+		return func(buf *bytes.Buffer, ctx *templateContext) {
+			buf.WriteString(strconv.FormatInt(min+rand.Int63n(max-min+1), 10))
+		}, nil
+	},
+	"randString": func(args []string) (templatePart, error) {
+		n, err := parseSingleIntArg("randString", args)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return func(buf *bytes.Buffer, ctx *templateContext) {
+			for i := 0; i < n; i++ {
+				buf.WriteByte(randomStringLetters[rand.Intn(len(randomStringLetters))])
+			}
+		}, nil
+	},
+	"uuid": noArgs("uuid", func(buf *bytes.Buffer, ctx *templateContext) {
+		var uuid [16]byte
+
+		for i := 0; i < len(uuid); i += 8 {
+			n := rand.Uint64()
+
+			for j := 0; j < 8; j++ {
+				uuid[i+j] = byte(n >> uint(j*8))
+			}
+		}
+
+		// Version 4 and RFC 4122 variant.
+		uuid[6] = uuid[6]&0x0f | 0x40
+		uuid[8] = uuid[8]&0x3f | 0x80
+
+		fmt.Fprintf(buf, "%x-%x-%x-%x-%x", uuid[0:4], uuid[4:6], uuid[6:8], uuid[8:10], uuid[10:])
+	}),
+	"seq": noArgs("seq", func(buf *bytes.Buffer, ctx *templateContext) {
+		buf.WriteString(strconv.FormatInt(ctx.seq, 10))
+	}),
+	"worker": noArgs("worker", func(buf *bytes.Buffer, ctx *templateContext) {
+		buf.WriteString(strconv.Itoa(ctx.worker))
+	}),
+	"timestamp": noArgs("timestamp", func(buf *bytes.Buffer, ctx *templateContext) {
+		buf.WriteString(strconv.FormatInt(ctx.now.Unix(), 10))
+	}),
+	"timestampMs": noArgs("timestampMs", func(buf *bytes.Buffer, ctx *templateContext) {
+		buf.WriteString(strconv.FormatInt(ctx.now.UnixNano()/int64(time.Millisecond), 10))
+	}),
+	"now": noArgs("now", func(buf *bytes.Buffer, ctx *templateContext) {
+		buf.WriteString(ctx.now.Format(time.RFC3339))
+	}),
+	"feed": func(args []string) (templatePart, error) {
+		if len(args) != 1 {
+			return nil, fmt.Errorf("feed expects a single column: %v", args)
+		}
+
+		column := args[0]
+
+		return func(buf *bytes.Buffer, ctx *templateContext) {
+			buf.WriteString(ctx.row[column])
+		}, nil
+	},
+	"var": func(args []string) (templatePart, error) {
+		if len(args) != 1 {
+			return nil, fmt.Errorf("var expects a single variable: %v", args)
+		}
+
+		name := args[0]
+
+		return func(buf *bytes.Buffer, ctx *templateContext) {
+			buf.WriteString(ctx.vars[name])
+		}, nil
+	},
+}
+
+func noArgs(name string, part templatePart) func(args []string) (templatePart, error) {
+	return func(args []string) (templatePart, error) {
+		if len(args) != 0 {
+			return nil, fmt.Errorf("%s does not expect any argument: %v", name, args)
+		}
+
+		return part, nil
+	}
+}
+
+func parseSingleIntArg(name string, args []string) (int, error) {
+	if len(args) != 1 {
+		return 0, fmt.Errorf("%s expects a single argument: %v", name, args)
+	}
+
+	n, err := strconv.Atoi(args[0])
+
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("Wrong %s argument: %s", name, args[0])
+	}
+
+	return n, nil
+}
+
+// parseTemplate parses the expressions of a string. It returns nil when the
+// string does not contain any expression.
+func parseTemplate(s string) (*requestTemplate, error) {
+	matches := templateExpression.FindAllStringSubmatchIndex(s, -1)
+
+	if len(matches) == 0 {
+		return nil, nil
+	}
+
+	t := &requestTemplate{}
+	last := 0
+
+	for _, match := range matches {
+		t.addLiteral(s[last:match[0]])
+		last = match[1]
+
+		// Only the expression is quoted in the errors as the string can be
+		// a large body.
+		expression, content := s[match[0]:match[1]], s[match[2]:match[3]]
+
+		if strings.HasPrefix(content, `"`) {
+			literal, err := strconv.Unquote(content)
+
+			if err != nil {
+				return nil, fmt.Errorf("Wrong template string %s", expression)
+			}
+
+			t.addLiteral(literal)
+			continue
+		}
+
+		fields := strings.Fields(content)
+
+		if len(fields) == 0 {
+			return nil, fmt.Errorf("Empty template expression %s", expression)
+		}
+
+		function, ok := templateFunctions[fields[0]]
+
+		if !ok {
+			return nil, fmt.Errorf("Unknown template function %s in %s", fields[0], expression)
+		}
+
+		part, err := function(fields[1:])
+
+		if err != nil {
+			return nil, err
+		}
+
+		switch fields[0] {
+		case "feed":
+			t.columns = append(t.columns, fields[1])
+		case "var":
+			t.vars = append(t.vars, fields[1])
+		}
+
+		t.parts = append(t.parts, part)
+	}
+
+	t.addLiteral(s[last:])
+
+	return t, nil
+}
+
+func (t *requestTemplate) addLiteral(literal string) {
+	if literal == "" {
+		return
+	}
+
+	t.parts = append(t.parts, func(buf *bytes.Buffer, ctx *templateContext) {
+		buf.WriteString(literal)
+	})
+}
+
+func (t *requestTemplate) executeBytes(ctx *templateContext) []byte {
+	buf := &bytes.Buffer{}
+
+	for _, part := range t.parts {
+		part(buf, ctx)
+	}
+
+	return buf.Bytes()
+}
+
+func (t *requestTemplate) execute(ctx *templateContext) string {
+	return string(t.executeBytes(ctx))
+}
+
+// requestTemplates contains the parsed templates of a URL or of the benchmark.
+// Only the values containing expressions have a template.
+type requestTemplates struct {
+	addr, rawCookie, body *requestTemplate
+	headers, data         map[string]*requestTemplate
+	// Templates of the part values by the index of the part.
+	parts map[int]*requestTemplate
+}
+
+// newURLTemplates parses the templates of a URL. It returns nil when the URL
+// does not contain any expression.
+func newURLTemplates(u *URL) (*requestTemplates, error) {
+	t := &requestTemplates{}
+	empty := true
+
+	add := func(target **requestTemplate, value string) error {
+		tpl, err := parseTemplate(value)
+
+		if tpl != nil {
+			*target = tpl
+			empty = false
+		}
+
+		return err
+	}
+
+	if err := add(&t.addr, u.Addr); err != nil {
+		return nil, err
+	}
+
+	if err := add(&t.rawCookie, u.RawCookie); err != nil {
+		return nil, err
+	}
+
+	if !u.RawBody {
+		if err := add(&t.body, string(u.Body)); err != nil {
+			return nil, err
+		}
+	}
+
+	headers, err := parseTemplateMap(u.Headers)
+
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := parseTemplateMap(u.Data)
+
+	if err != nil {
+		return nil, err
+	}
+
+	t.headers, t.data, t.parts = headers, data, make(map[int]*requestTemplate)
+
+	for index, part := range u.Parts {
+		var tpl *requestTemplate
+
+		if err := add(&tpl, part.Value); err != nil {
+			return nil, err
+		}
+
+		if tpl != nil {
+			t.parts[index] = tpl
+		}
+	}
+
+	if empty && len(headers) == 0 && len(data) == 0 {
+		return nil, nil
+	}
+
+	return t, nil
+}
+
+// newBenchTemplates parses the templates of the headers and the raw cookie of
+// the benchmark. It returns nil when they do not contain any expression.
+func newBenchTemplates(b *Bench) (*requestTemplates, error) {
+	headers, err := parseTemplateMap(b.Headers)
+
+	if err != nil {
+		return nil, err
+	}
+
+	rawCookie, err := parseTemplate(b.RawCookie)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(headers) == 0 && rawCookie == nil {
+		return nil, nil
+	}
+
+	return &requestTemplates{headers: headers, rawCookie: rawCookie}, nil
+}
+
+func parseTemplateMap(values map[string]string) (map[string]*requestTemplate, error) {
+	templates := make(map[string]*requestTemplate)
+
+	for key, value := range values {
+		tpl, err := parseTemplate(value)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if tpl != nil {
+			templates[key] = tpl
+		}
+	}
+
+	return templates, nil
+}
+
+// render returns a copy of the URL with its templates evaluated. It returns
+// the URL itself when there is no template.
+func (t *requestTemplates) render(u *URL, ctx *templateContext) *URL {
+	if t == nil {
+		return u
+	}
+
+	r := *u
+
+	if t.addr != nil {
+		r.Addr = t.addr.execute(ctx)
+	}
+
+	if t.rawCookie != nil {
+		r.RawCookie = t.rawCookie.execute(ctx)
+	}
+
+	if t.body != nil {
+		r.Body = t.body.executeBytes(ctx)
+	}
+
+	r.Headers = t.renderMap(t.headers, u.Headers, ctx)
+	r.Data = t.renderMap(t.data, u.Data, ctx)
+
+	if len(t.parts) > 0 {
+		r.Parts = make([]*Part, len(u.Parts))
+
+		for index, part := range u.Parts {
+			r.Parts[index] = part
+
+			if tpl, ok := t.parts[index]; ok {
+				renderedPart := *part
+				renderedPart.Value = tpl.execute(ctx)
+				r.Parts[index] = &renderedPart
+			}
+		}
+	}
+
+	return &r
+}
+
+// renderMap returns a copy of values with its templates evaluated. It returns
+// values itself when there is no template.
+func (t *requestTemplates) renderMap(templates map[string]*requestTemplate, values map[string]string, ctx *templateContext) map[string]string {
+	if len(templates) == 0 {
+		return values
+	}
+
+	rendered := make(map[string]string, len(values))
+
+	for key, value := range values {
+		rendered[key] = value
+
+		if tpl, ok := templates[key]; ok {
+			rendered[key] = tpl.execute(ctx)
+		}
+	}
+
+	return rendered
+}
+
+// feedColumns returns the feeder columns used by the templates.
+func (t *requestTemplates) feedColumns() []string {
+	columns := []string{}
+
+	for _, tpl := range t.all() {
+		columns = append(columns, tpl.columns...)
+	}
+
+	return columns
+}
+
+// variables returns the scenario variables used by the templates.
+func (t *requestTemplates) variables() []string {
+	vars := []string{}
+
+	for _, tpl := range t.all() {
+		vars = append(vars, tpl.vars...)
+	}
+
+	return vars
+}
+
+func (t *requestTemplates) all() []*requestTemplate {
+	all := []*requestTemplate{}
+
+	if t == nil {
+		return all
+	}
+
+	templates := []*requestTemplate{t.addr, t.rawCookie, t.body}
+
+	for _, maps := range []map[string]*requestTemplate{t.headers, t.data} {
+		for _, tpl := range maps {
+			templates = append(templates, tpl)
+		}
+	}
+
+	for _, tpl := range t.parts {
+		templates = append(templates, tpl)
+	}
+
+	for _, tpl := range templates {
+		if tpl != nil {
+			all = append(all, tpl)
+		}
+	}
+
+	return all
+}
+
+func (t *requestTemplates) renderHeaders(headers map[string]string, ctx *templateContext) map[string]string {
+	if t == nil {
+		return headers
+	}
+
+	return t.renderMap(t.headers, headers, ctx)
+}
+
+func (t *requestTemplates) renderRawCookie(rawCookie string, ctx *templateContext) string {
+	if t == nil || t.rawCookie == nil {
+		return rawCookie
+	}
+
+	return t.rawCookie.execute(ctx)
+}
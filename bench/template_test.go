@@ -0,0 +1,127 @@
+package bench
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestTemplate(t *testing.T) {
+	ctx := &templateContext{seq: 42, worker: 3, now: time.Unix(1500000000, 5e8)}
+
+	templates := map[string]string{
+		"/users/{{seq}}":                    "^/users/42$",
+		"{{ worker }}-{{seq}}":              "^3-42$",
+		"{{randInt 5 7}}":                   "^[5-7]$",
+		"id={{randString 12}}":              "^id=[a-zA-Z0-9]{12}$",
+		"{{uuid}}":                          "^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$",
+		"{{timestamp}}/{{timestampMs}}":     "^1500000000/1500000000500$",
+		"{{now}}":                           "^" + regexp.QuoteMeta(ctx.now.Format(time.RFC3339)) + "$",
+		`{"id": {{seq}}, "name": "static"}`: `^\{"id": 42, "name": "static"\}$`,
+		`{{"{{"}}seq}} {{seq}}`:             `^\{\{seq\}\} 42$`,
+		`{{ "}}" }}{{"\"x\""}}`:             `^\}\}"x"$`,
+	}
+
+	for template, expected := range templates {
+		tpl, err := parseTemplate(template)
+
+		if err != nil || tpl == nil {
+			t.Fatalf("Unexpected result for %s: %v", template, err)
+		}
+
+		if result := tpl.execute(ctx); !regexp.MustCompile(expected).MatchString(result) {
+			t.Errorf("Expected %s to match %s but got %s", template, expected, result)
+		}
+	}
+
+	if tpl, err := parseTemplate("/no/expression"); tpl != nil || err != nil {
+		t.Errorf("Expected no template but got %v and %v", tpl, err)
+	}
+
+	wrongTemplates := map[string]string{
+		"{{}}":                 "Empty template expression {{}}",
+		"{{foo}}":              "Unknown template function foo in {{foo}}",
+		"large {{foo 1}} body": "Unknown template function foo in {{foo 1}}",
+		`{{"a" b}}`:            `Wrong template string {{"a" b}}`,
+		"{{randInt 1}}":        "randInt expects min and max: [1]",
+		"{{randInt 5 1}}":      "Wrong randInt range: [5 1]",
+		"{{randString}}":       "randString expects a single argument: []",
+		"{{randString foo}}":   "Wrong randString argument: foo",
+		"{{uuid 1}}":           "uuid does not expect any argument: [1]",
+	}
+
+	for template, expected := range wrongTemplates {
+		if _, err := parseTemplate(template); err == nil || err.Error() != expected {
+			t.Errorf("Expected to get %q for %s but got %v", expected, template, err)
+		}
+	}
+}
+
+func TestURLTemplates(t *testing.T) {
+	withURL, err := WithURLSettings("localhost/users/{{seq}}",
+		"POST",
+		[]string{"id={{seq}}", "static=value"},
+		[]string{"X-Request-Id: {{seq}}", "X-Static: value"},
+		"session={{seq}}",
+		"")
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	b := NewBench(withURL, WithHeader("X-Worker", "{{worker}}"))
+	u := b.URLs[0]
+
+	if u.Addr != "https://localhost/users/{{seq}}" {
+		t.Errorf("Expected the template to be kept as the address but got %s", u.Addr)
+	}
+
+	for seq := 1; seq <= 2; seq++ {
+		req, _ := b.buildRequest(u, 7)
+		value := strconv.Itoa(seq)
+
+		if req.URL.String() != "https://localhost/users/"+value ||
+			req.Header.Get("X-Request-Id") != value ||
+			req.Header.Get("X-Static") != "value" ||
+			req.Header.Get("X-Worker") != "7" ||
+			req.Header.Get("Set-Cookie") != "session="+value {
+			t.Errorf("Unexpected request %d: %s %v", seq, req.URL, req.Header)
+		}
+
+		req.ParseForm()
+
+		if req.PostForm.Get("id") != value || req.PostForm.Get("static") != "value" {
+			t.Errorf("Unexpected data for request %d: %v", seq, req.PostForm)
+		}
+	}
+
+	if u.Headers["X-Request-Id"] != "{{seq}}" || u.Data["id"] != "{{seq}}" {
+		t.Error("Expected the URL templates to be left untouched")
+	}
+
+	if _, err := WithURLSettings("localhost/{{foo}}", "GET", []string{}, []string{}, "", ""); err == nil {
+		t.Error("Expected to get an error for an unknown template function")
+	}
+
+	if _, err := WithURLSettings("localhost", "GET", []string{}, []string{"X-Id: {{seq 1}}"}, "", ""); err == nil {
+		t.Error("Expected to get an error for a wrong header template")
+	}
+
+	if _, err := WithHeaderString("X-Id: {{randInt}}"); err == nil {
+		t.Error("Expected to get an error for a wrong header template")
+	}
+}
+
+func TestBenchTemplatesError(t *testing.T) {
+	b := NewBench(WithURL(&URL{Addr: "http://localhost", Method: "GET"}),
+		WithHeader("X-Worker", "{{worker}}"),
+		WithRawCookie("session={{foo}}"))
+
+	expected := "Unknown template function foo in {{foo}}"
+
+	if err := b.Exec(context.Background()); err == nil || err.Error() != expected {
+		t.Errorf("Expected to get %q but got %v", expected, err)
+	}
+}
@@ -0,0 +1,110 @@
+package bench
+
+import (
+	"crypto/tls"
+	"net/http/httptrace"
+	"sync"
+	"time"
+
+	"github.com/sasanrose/gbench/report"
+)
+
+// requestTrace records the time of each phase of a request using httptrace.
+// Hooks may be called from other goroutines (i.e. while dialing) hence the
+// lock.
+type requestTrace struct {
+	dnsStart, dnsDone         time.Time
+	connectStart, connectDone time.Time
+	tlsStart, tlsDone         time.Time
+	wroteRequest, firstByte   time.Time
+	// Whether a connection was obtained and whether it was an idle one.
+	gotConn, reused bool
+
+	lock sync.Mutex
+}
+
+func (t *requestTrace) clientTrace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) {
+			t.set(&t.dnsStart)
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			t.set(&t.dnsDone)
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			t.lock.Lock()
+			defer t.lock.Unlock()
+
+			t.gotConn, t.reused = true, info.Reused
+		},
+		ConnectStart: func(network, addr string) {
+			t.setOnce(&t.connectStart)
+		},
+		ConnectDone: func(network, addr string, err error) {
+			t.set(&t.connectDone)
+		},
+		TLSHandshakeStart: func() {
+			t.set(&t.tlsStart)
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			t.set(&t.tlsDone)
+		},
+		WroteRequest: func(httptrace.WroteRequestInfo) {
+			t.set(&t.wroteRequest)
+		},
+		GotFirstResponseByte: func() {
+			t.set(&t.firstByte)
+		},
+	}
+}
+
+func (t *requestTrace) set(field *time.Time) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	*field = time.Now()
+}
+
+// setOnce only keeps the first time as a request may try to connect to
+// several addresses.
+func (t *requestTrace) setOnce(field *time.Time) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if field.IsZero() {
+		*field = time.Now()
+	}
+}
+
+// connection returns whether the request reused a connection. It returns false
+// as the second value when no connection was obtained (i.e. a dial error).
+func (t *requestTrace) connection() (bool, bool) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	return t.reused, t.gotConn
+}
+
+// phaseTimes returns the time spent in each phase given the time the body was
+// completely read. Phases which did not happen (i.e. connect for a reused
+// connection) are zero.
+func (t *requestTrace) phaseTimes(bodyRead time.Time) report.PhaseTimes {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	return report.PhaseTimes{
+		DNS:             between(t.dnsStart, t.dnsDone),
+		Connect:         between(t.connectStart, t.connectDone),
+		TLSHandshake:    between(t.tlsStart, t.tlsDone),
+		TimeToFirstByte: between(t.wroteRequest, t.firstByte),
+		Download:        between(t.firstByte, bodyRead),
+	}
+}
+
+func between(start, end time.Time) time.Duration {
+	if start.IsZero() || end.IsZero() || end.Before(start) {
+		return 0
+	}
+
+	return end.Sub(start)
+}
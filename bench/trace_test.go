@@ -0,0 +1,46 @@
+package bench
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sasanrose/gbench/report"
+)
+
+func TestPhaseTimes(t *testing.T) {
+	start := time.Now()
+
+	trace := &requestTrace{
+		dnsStart:     start,
+		dnsDone:      start.Add(1 * time.Millisecond),
+		connectStart: start.Add(1 * time.Millisecond),
+		connectDone:  start.Add(3 * time.Millisecond),
+		tlsStart:     start.Add(3 * time.Millisecond),
+		tlsDone:      start.Add(6 * time.Millisecond),
+		wroteRequest: start.Add(7 * time.Millisecond),
+		firstByte:    start.Add(11 * time.Millisecond),
+	}
+
+	expected := report.PhaseTimes{
+		DNS:             1 * time.Millisecond,
+		Connect:         2 * time.Millisecond,
+		TLSHandshake:    3 * time.Millisecond,
+		TimeToFirstByte: 4 * time.Millisecond,
+		Download:        5 * time.Millisecond,
+	}
+
+	if phases := trace.phaseTimes(start.Add(16 * time.Millisecond)); phases != expected {
+		t.Errorf("Expected %+v but got %+v", expected, phases)
+	}
+
+	reused := &requestTrace{wroteRequest: start, firstByte: start.Add(time.Millisecond)}
+	phases := reused.phaseTimes(start.Add(2 * time.Millisecond))
+
+	if phases.DNS != 0 || phases.Connect != 0 || phases.TLSHandshake != 0 {
+		t.Errorf("Expected zero for the phases which did not happen but got %+v", phases)
+	}
+
+	if phases.TimeToFirstByte != time.Millisecond || phases.Download != time.Millisecond {
+		t.Errorf("Unexpected phases: %+v", phases)
+	}
+}
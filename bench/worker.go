@@ -0,0 +1,187 @@
+package bench
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// controlInterval is how often the closed model checks the duration, the load
+// stages and resizes the worker pools.
+const controlInterval = 100 * time.Millisecond
+
+// workerPool runs the workers of the closed model. Workers take requests from
+// a per URL budget so that each URL receives the requested number of requests.
+// Scenarios have a budget of iterations which follows the budgets of the URLs.
+type workerPool struct {
+	ctx     context.Context
+	b       *Bench
+	clients *clientPool
+
+	// Number of requests taken from the budget of each URL and scenario.
+	sent []int64
+	// Name of the current load stage.
+	stage atomic.Value
+	// Stop channels of the running workers. Each channel stops one worker
+	// per URL and scenario.
+	workers []chan struct{}
+	// Closed when all the requests are taken.
+	exhausted        chan struct{}
+	exhaustedBudgets int64
+
+	wg sync.WaitGroup
+}
+
+func newWorkerPool(ctx context.Context, b *Bench, clients *clientPool) *workerPool {
+	p := &workerPool{
+		ctx:       ctx,
+		b:         b,
+		clients:   clients,
+		sent:      make([]int64, len(b.URLs)+len(b.Scenarios)),
+		workers:   make([]chan struct{}, 0),
+		exhausted: make(chan struct{}),
+	}
+
+	p.stage.Store("")
+
+	if len(p.sent) == 0 && b.Requests > 0 {
+		close(p.exhausted)
+	}
+
+	return p
+}
+
+// resize starts or stops workers so that n workers run for each URL and
+// scenario. Stopped workers finish their in-flight request first.
+func (p *workerPool) resize(n int) {
+	for len(p.workers) < n {
+		stop := make(chan struct{})
+		p.workers = append(p.workers, stop)
+
+		for i, u := range p.b.URLs {
+			p.wg.Add(1)
+			go p.work(len(p.workers), i, u, stop)
+		}
+
+		for i, s := range p.b.Scenarios {
+			p.wg.Add(1)
+			go p.workScenario(len(p.workers), len(p.b.URLs)+i, s, stop)
+		}
+	}
+
+	for len(p.workers) > n {
+		close(p.workers[len(p.workers)-1])
+		p.workers = p.workers[:len(p.workers)-1]
+	}
+}
+
+// stop stops all the workers and waits for their in-flight requests.
+func (p *workerPool) stop() {
+	p.resize(0)
+	p.wg.Wait()
+}
+
+func (p *workerPool) setStage(name string) {
+	p.stage.Store(name)
+}
+
+// sentRequests returns the number of requests sent per URL.
+func (p *workerPool) sentRequests() int {
+	if len(p.sent) == 0 {
+		return 0
+	}
+
+	sent := int(atomic.LoadInt64(&p.sent[0]))
+
+	if p.b.Requests > 0 && sent > p.b.Requests {
+		return p.b.Requests
+	}
+
+	return sent
+}
+
+// work sends the requests of a URL until it is stopped. Worker ids start from
+// 1 and are reused by workers started after a resize.
+func (p *workerPool) work(worker, urlIndex int, u *URL, stop chan struct{}) {
+	defer p.wg.Done()
+
+	client := p.client(worker, urlIndex)
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-p.ctx.Done():
+			return
+		default:
+		}
+
+		if !p.take(urlIndex) {
+			return
+		}
+
+		req, err := p.b.buildRequest(u, worker)
+
+		if err != nil {
+			p.b.addRequestError(u.Addr, err, p.stage.Load().(string))
+			continue
+		}
+
+		if req == nil {
+			return
+		}
+
+		req = req.WithContext(p.ctx)
+		p.b.runBench(client, req, u.Addr, u.Assertions, p.stage.Load().(string))
+	}
+}
+
+// workScenario runs the iterations of a scenario as a virtual user until it
+// is stopped.
+func (p *workerPool) workScenario(worker, index int, s *Scenario, stop chan struct{}) {
+	defer p.wg.Done()
+
+	client := p.client(worker, index)
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-p.ctx.Done():
+			return
+		default:
+		}
+
+		if !p.take(index) {
+			return
+		}
+
+		if !p.b.runScenario(p.ctx, client, s, worker, p.stage.Load().(string)) {
+			return
+		}
+	}
+}
+
+// client returns the HTTP client of a worker of a URL or a scenario. Every
+// worker of every URL and scenario has a slot of its own.
+func (p *workerPool) client(worker, index int) *http.Client {
+	return p.clients.get((worker-1)*len(p.sent) + index)
+}
+
+// take takes a request from the budget of a URL or an iteration from the
+// budget of a scenario. It returns false when there is nothing left.
+func (p *workerPool) take(index int) bool {
+	n := atomic.AddInt64(&p.sent[index], 1)
+
+	if p.b.Requests == 0 || n <= int64(p.b.Requests) {
+		return true
+	}
+
+	if n == int64(p.b.Requests)+1 && atomic.AddInt64(&p.exhaustedBudgets, 1) == int64(len(p.sent)) {
+		close(p.exhausted)
+	}
+
+	return false
+}
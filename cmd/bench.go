@@ -3,6 +3,7 @@ package cmd
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"os"
@@ -14,27 +15,56 @@ import (
 )
 
 func runBench(configurations []func(b *bench.Bench)) {
-	if _, err := os.Stat(outputPath); err == nil && !forceOverWrite {
-		exitWithError(fmt.Sprintf("%s already exists. Use -F to overwrite.", outputPath))
+	outputFile := createOutputFile()
+
+	defer outputFile.Close()
+
+	b, result, err := prepareBench(configurations)
+
+	if err != nil {
+		exitWithError(err.Error())
 	}
 
-	outputFile, err := os.Create(outputPath)
+	parsedThresholds, err := getThresholds(thresholds)
 
 	if err != nil {
-		exitWithError(fmt.Sprintf("Could not open %s: %v\n", outputPath, err))
+		exitWithError(err.Error())
 	}
 
-	defer outputFile.Close()
+	ctx := signalContext()
 
+	if err := b.Exec(ctx); err != nil && err != context.Canceled {
+		exitWithError(fmt.Sprintf("Could not execute the benchmark: %v\n", err))
+	}
+
+	log.Printf("Storing the report in %s...", outputPath)
+	encoder := json.NewEncoder(outputFile)
+	encoder.Encode(result)
+
+	if len(parsedThresholds) > 0 && !checkThresholds(os.Stdout, parsedThresholds, result) {
+		os.Exit(thresholdsExitCode)
+	}
+}
+
+// prepareBench creates a benchmark with the global configurations and the
+// result it reports to.
+func prepareBench(configurations []func(*bench.Bench)) (*bench.Bench, *report.Result, error) {
 	result := &report.Result{}
 	result.Init(concurrency)
+	result.SetConcurrencyWindow(concurrencyWindow)
+	result.SetTimeSeriesInterval(timeSeriesBucket)
 
-	configurations, err = appendGlobalConfigurations(configurations, result)
+	configurations, err := appendGlobalConfigurations(configurations, result)
 
 	if err != nil {
-		exitWithError(err.Error())
+		return nil, nil, err
 	}
 
+	return bench.NewBench(configurations...), result, nil
+}
+
+// signalContext returns a context which is canceled on SIGINT or SIGTERM.
+func signalContext() context.Context {
 	ctx, cancelFunc := context.WithCancel(context.Background())
 	sigs := make(chan os.Signal, 1)
 
@@ -46,24 +76,52 @@ func runBench(configurations []func(b *bench.Bench)) {
 		cancelFunc()
 	}()
 
-	b := bench.NewBench(configurations...)
-	b.Exec(ctx)
+	return ctx
+}
+
+// createOutputFile creates the file to store the report in. An existing file
+// is only overwritten when forced.
+func createOutputFile() *os.File {
+	if _, err := os.Stat(outputPath); err == nil && !forceOverWrite {
+		exitWithError(fmt.Sprintf("%s already exists. Use -F to overwrite.", outputPath))
+	}
 
-	log.Printf("Storing the report in %s...", outputPath)
-	encoder := json.NewEncoder(outputFile)
-	encoder.Encode(result)
+	outputFile, err := os.Create(outputPath)
+
+	if err != nil {
+		exitWithError(fmt.Sprintf("Could not open %s: %v\n", outputPath, err))
+	}
+
+	return outputFile
 }
 
 func appendGlobalConfigurations(configurations []func(*bench.Bench), result *report.Result) ([]func(*bench.Bench), error) {
 	configurations = append(configurations, []func(*bench.Bench){
 		bench.WithConcurrency(concurrency),
 		bench.WithRequests(requests),
+		bench.WithDuration(duration),
+		bench.WithMaxInFlight(maxInFlight),
+		bench.WithExpectedInterval(expectedInterval),
 		bench.WithConnectionTimeout(connectionTimeout),
 		bench.WithResponseTimeout(responseTimeout),
+		bench.WithMaxIdleConnsPerHost(maxIdleConnsPerHost),
+		bench.WithMaxConnsPerHost(maxConnsPerHost),
+		bench.WithIdleConnTimeout(idleConnTimeout),
+		bench.WithMaxRedirects(maxRedirects),
 		bench.WithReport(result),
 		bench.WithOutput(os.Stdout),
 	}...)
 
+	if rate != "" {
+		rateConfig, err := bench.WithRateString(rate)
+
+		if err != nil {
+			return []func(*bench.Bench){}, fmt.Errorf("Error with rate: %v", err)
+		}
+
+		configurations = append(configurations, rateConfig)
+	}
+
 	for _, statusCode := range successStatusCodes {
 		statusCodeConfig := bench.WithSuccessStatusCode(statusCode)
 
@@ -98,10 +156,106 @@ func appendGlobalConfigurations(configurations []func(*bench.Bench), result *rep
 		configurations = append(configurations, bench.WithRawCookie(rawCookie))
 	}
 
+	if disableKeepAlives {
+		configurations = append(configurations, bench.WithDisableKeepAlives())
+	}
+
+	if disableRedirects {
+		configurations = append(configurations, bench.WithDisableRedirects())
+	}
+
+	if protocol != "" {
+		protocolConfig, err := bench.WithProtocol(protocol)
+
+		if err != nil {
+			return []func(*bench.Bench){}, fmt.Errorf("Error with protocol: %v", err)
+		}
+
+		configurations = append(configurations, protocolConfig)
+	}
+
+	if streamsPerConnection > 0 {
+		configurations = append(configurations, bench.WithStreamsPerConnection(streamsPerConnection))
+	}
+
+	if feederPath != "" {
+		feeder, err := bench.NewFeeder(feederPath, feederMode)
+
+		if err != nil {
+			return []func(*bench.Bench){}, fmt.Errorf("Error with feeder: %v", err)
+		}
+
+		configurations = append(configurations, bench.WithFeeder(feeder))
+	}
+
+	tlsConfigurations, err := getTLSConfig()
+
+	if err != nil {
+		return []func(*bench.Bench){}, fmt.Errorf("Error with TLS: %v", err)
+	}
+
+	return append(configurations, tlsConfigurations...), nil
+}
+
+func getTLSConfig() ([]func(*bench.Bench), error) {
+	configurations := make([]func(*bench.Bench), 0)
+
+	if caCertPath != "" {
+		caConfig, err := bench.WithCACert(caCertPath)
+
+		if err != nil {
+			return []func(*bench.Bench){}, err
+		}
+
+		configurations = append(configurations, caConfig)
+	}
+
+	if clientCertPath != "" || clientKeyPath != "" {
+		if clientCertPath == "" || clientKeyPath == "" {
+			return []func(*bench.Bench){}, errors.New("Client certificate and key should be used together")
+		}
+
+		certConfig, err := bench.WithClientCert(clientCertPath, clientKeyPath)
+
+		if err != nil {
+			return []func(*bench.Bench){}, err
+		}
+
+		configurations = append(configurations, certConfig)
+	}
+
+	if insecure {
+		configurations = append(configurations, bench.WithInsecureSkipVerify())
+	}
+
+	if tlsServerName != "" {
+		configurations = append(configurations, bench.WithServerName(tlsServerName))
+	}
+
+	if tlsMinVersion != "" || tlsMaxVersion != "" {
+		versionsConfig, err := bench.WithTLSVersions(tlsMinVersion, tlsMaxVersion)
+
+		if err != nil {
+			return []func(*bench.Bench){}, err
+		}
+
+		configurations = append(configurations, versionsConfig)
+	}
+
+	if len(cipherSuites) > 0 {
+		suitesConfig, err := bench.WithCipherSuites(cipherSuites)
+
+		if err != nil {
+			return []func(*bench.Bench){}, err
+		}
+
+		configurations = append(configurations, suitesConfig)
+	}
+
 	return configurations, nil
 }
 
 func exitWithError(msg string) {
-	fmt.Fprintf(os.Stderr, msg)
+	fmt.Fprint(os.Stderr, msg)
 	os.Exit(2)
 }
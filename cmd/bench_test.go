@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"crypto/tls"
 	"os"
 	"os/exec"
 	"testing"
@@ -29,6 +30,18 @@ func TestGlobalConfigurations(t *testing.T) {
 		t.Errorf("Expected requests of %d but got %d", requests, b.Requests)
 	}
 
+	if b.Duration != duration {
+		t.Errorf("Expected duration of %s but got %s", duration, b.Duration)
+	}
+
+	if b.Rate != 500 || b.MaxInFlight != maxInFlight {
+		t.Errorf("Expected rate of 500 and max in-flight of %d but got %v and %d", maxInFlight, b.Rate, b.MaxInFlight)
+	}
+
+	if b.ExpectedInterval != expectedInterval {
+		t.Errorf("Expected interval of %s but got %s", expectedInterval, b.ExpectedInterval)
+	}
+
 	if b.ConnectionTimeout != connectionTimeout {
 		t.Errorf("Expected ConnectionTimeout of %s but got %s", connectionTimeout, b.ConnectionTimeout)
 	}
@@ -37,6 +50,14 @@ func TestGlobalConfigurations(t *testing.T) {
 		t.Errorf("Expected ResponseTimeout of %s but got %s", responseTimeout, b.ResponseTimeout)
 	}
 
+	if !b.DisableKeepAlives || b.MaxIdleConnsPerHost != maxIdleConnsPerHost || b.MaxConnsPerHost != maxConnsPerHost || b.IdleConnTimeout != idleConnTimeout {
+		t.Errorf("Unexpected connection settings: %v %d %d %s", b.DisableKeepAlives, b.MaxIdleConnsPerHost, b.MaxConnsPerHost, b.IdleConnTimeout)
+	}
+
+	if !b.DisableRedirects || b.MaxRedirects != maxRedirects {
+		t.Errorf("Unexpected redirect settings: %v %d", b.DisableRedirects, b.MaxRedirects)
+	}
+
 	if b.Proxy != proxyURL {
 		t.Errorf("Expected Proxy of %s but got %s", proxyURL, b.Proxy)
 	}
@@ -91,9 +112,113 @@ func TestWrongAuth(t *testing.T) {
 	}
 }
 
+func TestWrongRate(t *testing.T) {
+	expected := "Error with rate: Wrong rate format: fast"
+	result := setSharedVars()
+	headers = []string{}
+	authUserPass = ""
+	rate = "fast"
+
+	configurations := make([]func(*bench.Bench), 0)
+	_, err := appendGlobalConfigurations(configurations, result)
+
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected to get %q but got %v", expected, err)
+	}
+}
+
+func TestWrongFeeder(t *testing.T) {
+	expected := "Error with feeder: Wrong feeder mode: fast"
+	result := setSharedVars()
+	headers = []string{}
+	authUserPass = ""
+	feederPath, feederMode = "users.csv", "fast"
+
+	configurations := make([]func(*bench.Bench), 0)
+	_, err := appendGlobalConfigurations(configurations, result)
+
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected to get %q but got %v", expected, err)
+	}
+}
+
+func TestTLSConfig(t *testing.T) {
+	result := setSharedVars()
+	headers = []string{}
+	authUserPass = ""
+	insecure, tlsServerName, tlsMinVersion = true, "example.com", "1.2"
+
+	defer setSharedVars()
+
+	configurations, err := appendGlobalConfigurations([]func(*bench.Bench){}, result)
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	b := bench.NewBench(configurations...)
+
+	if b.TLSConfig == nil || !b.TLSConfig.InsecureSkipVerify || b.TLSConfig.ServerName != "example.com" || b.TLSConfig.MinVersion != tls.VersionTLS12 {
+		t.Errorf("Unexpected TLS config: %+v", b.TLSConfig)
+	}
+
+	wrongConfigs := map[string]func(){
+		"Error with TLS: Client certificate and key should be used together":                   func() { clientCertPath = "cert.pem" },
+		"Error with TLS: Wrong TLS version: 2.0 (Accepted versions are 1.0, 1.1, 1.2 and 1.3)": func() { tlsMaxVersion = "2.0" },
+		"Error with TLS: Unknown cipher suite: TLS_WRONG":                                      func() { cipherSuites = []string{"TLS_WRONG"} },
+	}
+
+	for expected, config := range wrongConfigs {
+		setSharedVars()
+		config()
+
+		if _, err := appendGlobalConfigurations([]func(*bench.Bench){}, result); err == nil || err.Error() != expected {
+			t.Errorf("Expected to get %q but got %v", expected, err)
+		}
+	}
+}
+
+func TestProtocolConfig(t *testing.T) {
+	result := setSharedVars()
+	headers = []string{}
+	authUserPass = ""
+	protocol, streamsPerConnection = bench.ProtocolH2C, 10
+
+	defer setSharedVars()
+
+	configurations, err := appendGlobalConfigurations([]func(*bench.Bench){}, result)
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if b := bench.NewBench(configurations...); b.Protocol != bench.ProtocolH2C || b.StreamsPerConnection != 10 {
+		t.Errorf("Unexpected protocol settings: %s %d", b.Protocol, b.StreamsPerConnection)
+	}
+
+	protocol = "spdy"
+	expected := "Error with protocol: Wrong protocol: spdy (Accepted protocols are http1, http2 and h2c)"
+
+	if _, err := appendGlobalConfigurations([]func(*bench.Bench){}, result); err == nil || err.Error() != expected {
+		t.Errorf("Expected to get %q but got %v", expected, err)
+	}
+}
+
 func setSharedVars() *report.Result {
+	feederPath, feederMode = "", ""
+	caCertPath, clientCertPath, clientKeyPath = "", "", ""
+	tlsServerName, tlsMinVersion, tlsMaxVersion = "", "", ""
+	cipherSuites, insecure = []string{}, false
+	protocol, streamsPerConnection = "", 0
+	disableKeepAlives, maxIdleConnsPerHost, maxConnsPerHost, idleConnTimeout = true, 10, 20, time.Minute
+	disableRedirects, maxRedirects = true, 5
+	thresholds = []string{}
 	concurrency = 5
 	requests = 100
+	duration = time.Minute
+	rate = "500/s"
+	maxInFlight = 50
+	expectedInterval = 10 * time.Millisecond
 	connectionTimeout = 1 * time.Second
 	responseTimeout = 5 * time.Second
 	successStatusCodes = []int{200, 201}
@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/sasanrose/gbench/report"
+	"github.com/spf13/cobra"
+)
+
+// thresholdsExitCode is the exit code when a threshold fails. It differs from
+// the exit code of the other errors so CI pipelines can tell them apart.
+const thresholdsExitCode = 3
+
+var checkCmd = &cobra.Command{
+	Use:   "check report.json",
+	Short: "Check the report of a benchmark against thresholds",
+	Long: `Checks the report generated by exec or json command against thresholds and
+exits with code 3 when any of them fails.
+
+Sample usage:
+gbench check report.json --threshold 'avg_latency{url=/api} < 200ms' --threshold 'success_rate > 99.5%'
+gbench check report.json --config config.json`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if thresholdsConfigPath != "" {
+			configThresholds, err := readConfigThresholds(thresholdsConfigPath)
+
+			if err != nil {
+				exitWithError(err.Error())
+			}
+
+			thresholds = append(thresholds, configThresholds...)
+		}
+
+		parsedThresholds, err := getThresholds(thresholds)
+
+		if err != nil {
+			exitWithError(err.Error())
+		}
+
+		if len(parsedThresholds) == 0 {
+			exitWithError("No threshold is provided")
+		}
+
+		if !checkThresholds(os.Stdout, parsedThresholds, readResult(args[0])) {
+			os.Exit(thresholdsExitCode)
+		}
+	},
+}
+
+// readConfigThresholds reads the thresholds block of a JSON configuration.
+func readConfigThresholds(path string) ([]string, error) {
+	file, err := fs.Open(path)
+
+	if err != nil {
+		return []string{}, fmt.Errorf("Could not open %q: %v", path, err)
+	}
+
+	defer file.Close()
+
+	config := &JSONConfig{}
+
+	if err := json.NewDecoder(file).Decode(config); err != nil {
+		return []string{}, fmt.Errorf("Invalid file %s: %v", path, err)
+	}
+
+	return config.Thresholds, nil
+}
+
+func getThresholds(expressions []string) ([]*report.Threshold, error) {
+	parsedThresholds := make([]*report.Threshold, 0, len(expressions))
+
+	for _, expression := range expressions {
+		threshold, err := report.NewThreshold(expression)
+
+		if err != nil {
+			return []*report.Threshold{}, fmt.Errorf("Error with threshold: %v", err)
+		}
+
+		parsedThresholds = append(parsedThresholds, threshold)
+	}
+
+	return parsedThresholds, nil
+}
+
+// checkThresholds checks the thresholds against a result, writes a pass/fail
+// summary and reports whether all of them passed.
+func checkThresholds(w io.Writer, thresholds []*report.Threshold, result *report.Result) bool {
+	checked, failed := 0, 0
+
+	fmt.Fprintln(w, "Thresholds:")
+
+	for _, threshold := range thresholds {
+		for _, thresholdResult := range threshold.Check(result) {
+			status := "PASS"
+
+			if !thresholdResult.Passed {
+				status = "FAIL"
+				failed++
+			}
+
+			checked++
+
+			fmt.Fprintf(w, "  %s  %s%s\n", status, threshold.Expression, getThresholdDetails(thresholdResult))
+		}
+	}
+
+	if failed > 0 {
+		fmt.Fprintf(w, "%d of %d thresholds failed\n", failed, checked)
+		return false
+	}
+
+	fmt.Fprintf(w, "All %d thresholds passed\n", checked)
+
+	return true
+}
+
+func getThresholdDetails(r *report.ThresholdResult) string {
+	if r.Err != nil {
+		return fmt.Sprintf(" (%v)", r.Err)
+	}
+
+	if r.URL != "" {
+		return fmt.Sprintf(" (%s: %s)", r.URL, r.Value)
+	}
+
+	return fmt.Sprintf(" (%s)", r.Value)
+}
+
+func init() {
+	rootCmd.AddCommand(checkCmd)
+
+	initCheckFlags()
+}
@@ -0,0 +1,8 @@
+package cmd
+
+var thresholdsConfigPath string
+
+func initCheckFlags() {
+	checkCmd.Flags().StringArrayVar(&thresholds, "threshold", []string{}, thresholdUsage)
+	checkCmd.Flags().StringVar(&thresholdsConfigPath, "config", "", "Path to a JSON configuration whose thresholds block is checked as well.")
+}
@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sasanrose/gbench/report"
+)
+
+func TestCheckThresholds(t *testing.T) {
+	r := &report.Result{}
+	r.Init(1)
+	r.AddSentRequest("http://testurl.com/api")
+	r.AddResponseTime("http://testurl.com/api", 100*time.Millisecond)
+	r.AddResponseStatusCode("http://testurl.com/api", 200, false)
+
+	parsedThresholds, err := getThresholds([]string{"avg_latency{url=/api} < 200ms", "timeouts == 0"})
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	output := &bytes.Buffer{}
+	expected := `Thresholds:
+  PASS  avg_latency{url=/api} < 200ms (http://testurl.com/api: 100ms)
+  PASS  timeouts == 0 (0)
+All 2 thresholds passed
+`
+
+	if !checkThresholds(output, parsedThresholds, r) || output.String() != expected {
+		t.Errorf("Expected %q but got %q", expected, output.String())
+	}
+
+	parsedThresholds, _ = getThresholds([]string{"success_rate > 99.5%", "requests{url=/missing} > 0"})
+	r.AddSentRequest("http://testurl.com/api")
+	r.AddResponseStatusCode("http://testurl.com/api", 500, true)
+
+	output.Reset()
+	expected = `Thresholds:
+  FAIL  success_rate > 99.5% (50.00%)
+  FAIL  requests{url=/missing} > 0 (No URL matches /missing)
+2 of 2 thresholds failed
+`
+
+	if checkThresholds(output, parsedThresholds, r) || output.String() != expected {
+		t.Errorf("Expected %q but got %q", expected, output.String())
+	}
+}
+
+func TestWrongThreshold(t *testing.T) {
+	expected := "Error with threshold: Wrong threshold metric: latency"
+
+	if _, err := getThresholds([]string{"timeouts == 0", "latency < 1s"}); err == nil || err.Error() != expected {
+		t.Errorf("Expected to get %q but got %v", expected, err)
+	}
+}
+
+func TestReadConfigThresholds(t *testing.T) {
+	oldFs := fs
+	mfs := &mockedFSType{}
+	fs = mfs
+
+	defer func() {
+		fs = oldFs
+	}()
+
+	mfs.file = &mockedFileType{bytes.NewBufferString(testJSON)}
+
+	configThresholds, err := readConfigThresholds("config.json")
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(configThresholds) != 2 || configThresholds[0] != "avg_latency{url=/test} < 200ms" {
+		t.Errorf("Unexpected thresholds: %v", configThresholds)
+	}
+
+	mfs.err = errors.New("Missing")
+	expected := `Could not open "config.json": Missing`
+
+	if _, err := readConfigThresholds("config.json"); err == nil || err.Error() != expected {
+		t.Errorf("Expected to get %q but got %v", expected, err)
+	}
+}
@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	renderer "github.com/sasanrose/gbench/render/driver"
+	"github.com/sasanrose/gbench/report"
+	"github.com/spf13/cobra"
+)
+
+var compareCmd = &cobra.Command{
+	Use:   "compare old.json new.json [newer.json...]",
+	Short: "Compare the reports of two or more benchmarks",
+	Long: `Compares the reports generated by exec or json command. Every report is
+compared to the first one and the URLs are matched by their address.
+
+Sample usage:
+gbench compare old.json new.json
+gbench compare old.json new.json newer.json
+gbench compare old.json new.json --percentiles 50,99,99.9 --threshold 10`,
+	Args: cobra.MinimumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		parsedPercentiles, err := parsePercentiles(comparePercentiles)
+
+		if err != nil {
+			exitWithError(err.Error())
+		}
+
+		results := make([]*report.Result, 0, len(args))
+
+		for _, path := range args {
+			results = append(results, readResult(path))
+		}
+
+		c := renderer.NewCliComparer(renderer.WithComparePercentiles(parsedPercentiles),
+			renderer.WithRegressionThreshold(regressionThreshold))
+
+		if err := c.Compare(args, results); err != nil {
+			exitWithError(fmt.Sprintf("Could not compare the reports: %v\n", err))
+		}
+	},
+}
+
+func readResult(path string) *report.Result {
+	file, err := os.Open(path)
+
+	if err != nil {
+		exitWithError(fmt.Sprintf("Could not open %s: %v.\n", path, err))
+	}
+
+	defer file.Close()
+
+	return decodeResult(file)
+}
+
+func init() {
+	rootCmd.AddCommand(compareCmd)
+
+	initCompareFlags()
+}
@@ -0,0 +1,11 @@
+package cmd
+
+var (
+	comparePercentiles  []string
+	regressionThreshold float64
+)
+
+func initCompareFlags() {
+	compareCmd.Flags().StringSliceVar(&comparePercentiles, "percentiles", []string{"50", "90", "95", "99"}, "Response time percentiles to compare (i.e. 50,99,99.9).")
+	compareCmd.Flags().Float64Var(&regressionThreshold, "threshold", 5, "Percent change from which a worse value is highlighted as a regression and a better value as an improvement.")
+}
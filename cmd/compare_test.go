@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sasanrose/gbench/report"
+)
+
+func TestReadResult(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gbench")
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	r := &report.Result{}
+	r.Init(1)
+	r.AddSentRequest("http://testurl.com")
+	r.AddResponseStatusCode("http://testurl.com", 200, false)
+
+	path := filepath.Join(dir, "report.json")
+	encoded, _ := json.Marshal(r)
+
+	if err := ioutil.WriteFile(path, encoded, 0644); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if result := readResult(path); result.TotalRequests != 1 || result.SuccessfulRequests != 1 {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+}
+
+func TestCompareMissingFile(t *testing.T) {
+	if os.Getenv("CRASH_TEST") == "1" {
+		readResult("/path/to/missing/report.json")
+		return
+	}
+
+	testExit(
+		t,
+		"TestCompareMissingFile",
+		"",
+	)
+}
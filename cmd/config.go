@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"encoding/json"
 	"net/http"
 	"time"
 )
@@ -14,24 +15,77 @@ var (
 
 	headers                            []string
 	authUserPass, proxyURL, rawCookie  string
-	concurrency, requests              int
+	rate, feederPath, feederMode       string
+	concurrency, requests, maxInFlight int
 	successStatusCodes                 []int
 	connectionTimeout, responseTimeout time.Duration
+	duration, expectedInterval         time.Duration
+
+	caCertPath, clientCertPath, clientKeyPath   string
+	tlsServerName, tlsMinVersion, tlsMaxVersion string
+	cipherSuites                                []string
+	insecure                                    bool
+
+	protocol             string
+	streamsPerConnection int
+
+	disableKeepAlives                    bool
+	maxIdleConnsPerHost, maxConnsPerHost int
+	idleConnTimeout                      time.Duration
+
+	disableRedirects bool
+	maxRedirects     int
 )
 
 // JSONConfig defines the configurations that can be set via JSON file.
 type JSONConfig struct {
-	Host            string        `json:"host"`
-	Concurrency     int           `json:"concurrency"`
-	Requests        int           `json:"requests"`
-	StatusCodes     []int         `json:"status-codes"`
-	AuthUserPass    string        `json:"user"`
-	Proxy           string        `json:"proxy"`
-	ConnectTimeout  time.Duration `json:"connect-timeout"`
-	ResponseTimeout time.Duration `json:"response-timeout"`
-	Headers         []string      `json:"headers"`
-	RawCookie       string        `json:"cookie"`
-	Paths           []*PathConfig `json:"paths"`
+	Host             string            `json:"host"`
+	Concurrency      int               `json:"concurrency"`
+	Requests         int               `json:"requests"`
+	Duration         time.Duration     `json:"duration"`
+	Rate             string            `json:"rate"`
+	MaxInFlight      int               `json:"max-in-flight"`
+	ExpectedInterval time.Duration     `json:"expected-interval"`
+	Stages           []*StageConfig    `json:"stages"`
+	StatusCodes      []int             `json:"status-codes"`
+	AuthUserPass     string            `json:"user"`
+	Proxy            string            `json:"proxy"`
+	ConnectTimeout   time.Duration     `json:"connect-timeout"`
+	ResponseTimeout  time.Duration     `json:"response-timeout"`
+	NoKeepAlive      bool              `json:"disable-keep-alives"`
+	MaxIdleConns     int               `json:"max-idle-conns-per-host"`
+	MaxConns         int               `json:"max-conns-per-host"`
+	IdleConnTimeout  time.Duration     `json:"idle-conn-timeout"`
+	NoRedirect       bool              `json:"disable-redirects"`
+	MaxRedirects     int               `json:"max-redirects"`
+	Headers          []string          `json:"headers"`
+	RawCookie        string            `json:"cookie"`
+	Feeder           *FeederConfig     `json:"feeder"`
+	TLS              *TLSConfig        `json:"tls"`
+	Protocol         string            `json:"protocol"`
+	Streams          int               `json:"streams-per-connection"`
+	Thresholds       []string          `json:"thresholds"`
+	Paths            []*PathConfig     `json:"paths"`
+	Scenarios        []*ScenarioConfig `json:"scenarios"`
+}
+
+// FeederConfig defines the feeder configurations that can be set via JSON
+// file.
+type FeederConfig struct {
+	Path string `json:"path"`
+	Mode string `json:"mode"`
+}
+
+// TLSConfig defines the TLS configurations that can be set via JSON file.
+type TLSConfig struct {
+	CACert       string   `json:"ca-cert"`
+	Cert         string   `json:"cert"`
+	Key          string   `json:"key"`
+	Insecure     bool     `json:"insecure"`
+	ServerName   string   `json:"server-name"`
+	MinVersion   string   `json:"min-version"`
+	MaxVersion   string   `json:"max-version"`
+	CipherSuites []string `json:"cipher-suites"`
 }
 
 // PathConfig defines the paths configurations that can be set via JSON file.
@@ -42,4 +96,52 @@ type PathConfig struct {
 	Data         []string `json:"data"`
 	RawCookie    string   `json:"cookie"`
 	AuthUserPass string   `json:"user"`
+	// Body can either be a string (where '@path' reads the body from a file)
+	// or a JSON value which is sent as is.
+	Body        json.RawMessage `json:"body"`
+	ContentType string          `json:"content-type"`
+	// TemplateBodyFile evaluates the template expressions of a body read
+	// from a file which is sent as is otherwise.
+	TemplateBodyFile bool `json:"template-body-file"`
+	// Form contains multipart/form-data parts in the same format as the form
+	// flag of exec command.
+	Form []string `json:"form"`
+	// Assert contains response assertions in the same format as the assert
+	// flag of exec command.
+	Assert []string `json:"assert"`
+}
+
+// ScenarioConfig defines the scenarios configurations that can be set via JSON
+// file.
+type ScenarioConfig struct {
+	Name  string        `json:"name"`
+	Steps []*StepConfig `json:"steps"`
+}
+
+// StepConfig defines the configurations of a step of a scenario. The request
+// of a step is configured the same way as a path.
+type StepConfig struct {
+	Name string `json:"name"`
+	PathConfig
+	// Extract contains the values to extract from the response of the step
+	// which can be used by the next steps (i.e. {{var token}}).
+	Extract []*ExtractConfig `json:"extract"`
+}
+
+// ExtractConfig defines the extraction of a value from the response of a
+// step. Type can be json, regex, header or cookie.
+type ExtractConfig struct {
+	Name       string `json:"name"`
+	Type       string `json:"type"`
+	Expression string `json:"expression"`
+}
+
+// StageConfig defines the load stages configurations that can be set via JSON
+// file.
+type StageConfig struct {
+	Name     string        `json:"name"`
+	Duration time.Duration `json:"duration"`
+	From     int           `json:"from"`
+	// To is optional and defaults to From.
+	To *int `json:"to"`
 }
@@ -0,0 +1,229 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/sasanrose/gbench/report"
+	"github.com/spf13/cobra"
+)
+
+var coordinatorCmd = &cobra.Command{
+	Use:   "coordinator",
+	Short: "Runs a benchmark on several workers",
+	Long: `Sends a benchmark to several workers started with worker command and the
+same token, starts them together and merges their reports into one. Every
+worker runs the whole benchmark, so the load is multiplied by the number of
+workers.
+
+Sample usage:
+gbench coordinator --workers host1:7777,host2:7777 --token secret json config.json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Usage()
+	},
+}
+
+var coordinatorJSONCmd = &cobra.Command{
+	Use:   "json config.json",
+	Short: "Runs the benchmark of a json configuration on the workers",
+	Long: `Runs the benchmark of a json configuration on the workers.
+Sample usage:
+
+gbench coordinator --workers host1:7777,host2:7777 --token secret json config.json`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(workers) == 0 {
+			exitWithError("No worker is provided")
+		}
+
+		if workerToken == "" {
+			exitWithError("No token is provided")
+		}
+
+		config, err := readJSONConfig(args[0])
+
+		if err != nil {
+			exitWithError(err.Error())
+		}
+
+		thresholds = append(thresholds, config.Thresholds...)
+
+		parsedThresholds, err := getThresholds(thresholds)
+
+		if err != nil {
+			exitWithError(err.Error())
+		}
+
+		outputFile := createOutputFile()
+
+		defer outputFile.Close()
+
+		result, err := coordinate(signalContext(), workers, workerToken, &WorkerConfig{
+			Config:            config,
+			TimeSeriesBucket:  timeSeriesBucket,
+			ConcurrencyWindow: concurrencyWindow,
+		})
+
+		if err != nil {
+			exitWithError(err.Error() + "\n")
+		}
+
+		log.Printf("Storing the merged report in %s...", outputPath)
+		json.NewEncoder(outputFile).Encode(result)
+
+		if len(parsedThresholds) > 0 && !checkThresholds(os.Stdout, parsedThresholds, result) {
+			os.Exit(thresholdsExitCode)
+		}
+	},
+}
+
+// coordinate prepares the benchmark on all the workers, starts them together
+// once they are all ready and merges their reports. The token is sent to the
+// workers with every request.
+func coordinate(ctx context.Context, workers []string, token string, config *WorkerConfig) (*report.Result, error) {
+	encodedConfig, err := json.Marshal(config)
+
+	if err != nil {
+		return nil, fmt.Errorf("Could not encode the configuration: %v", err)
+	}
+
+	for _, worker := range workers {
+		body, err := callWorker(ctx, worker, token, "/prepare", encodedConfig)
+
+		if err != nil {
+			return nil, fmt.Errorf("Could not prepare worker %s: %v", worker, err)
+		}
+
+		body.Close()
+	}
+
+	log.Printf("Starting the benchmark on %d workers...", len(workers))
+
+	// The reports are read even when ctx is canceled. The workers are stopped
+	// instead, so they respond with their partial reports.
+	runCtx, cancelFunc := context.WithCancel(context.Background())
+	defer cancelFunc()
+
+	done := make(chan struct{})
+	defer close(done)
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			stopWorkers(workers, token)
+		case <-done:
+		}
+	}()
+
+	results := make([]*report.Result, len(workers))
+	errs := make([]error, len(workers))
+	wg := &sync.WaitGroup{}
+
+	for i, worker := range workers {
+		wg.Add(1)
+
+		go func(i int, worker string) {
+			defer wg.Done()
+
+			results[i], errs[i] = startWorker(runCtx, worker, token)
+
+			// The benchmark is stopped on the other workers as the report
+			// can not be complete anymore.
+			if errs[i] != nil {
+				cancelFunc()
+			}
+		}(i, worker)
+	}
+
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			return nil, fmt.Errorf("Could not get the report of worker %s: %v", workers[i], err)
+		}
+	}
+
+	return mergeResults(workers, results)
+}
+
+func startWorker(ctx context.Context, worker, token string) (*report.Result, error) {
+	body, err := callWorker(ctx, worker, token, "/start", nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer body.Close()
+
+	result := &report.Result{}
+
+	if err := json.NewDecoder(body).Decode(result); err != nil {
+		return nil, fmt.Errorf("Invalid report: %v", err)
+	}
+
+	return result, nil
+}
+
+// stopWorkers stops the benchmark on all the workers.
+func stopWorkers(workers []string, token string) {
+	log.Print("Stopping the benchmark on the workers...")
+
+	for _, worker := range workers {
+		body, err := callWorker(context.Background(), worker, token, "/stop", nil)
+
+		if err != nil {
+			log.Printf("Could not stop worker %s: %v", worker, err)
+			continue
+		}
+
+		body.Close()
+	}
+}
+
+// callWorker sends a request to a worker and returns the body of its
+// successful response which the caller should close.
+func callWorker(ctx context.Context, worker, token, path string, body []byte) (io.ReadCloser, error) {
+	if !strings.Contains(worker, "://") {
+		worker = "http://" + worker
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(worker, "/")+path, bytes.NewReader(body))
+
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := http.DefaultClient.Do(req)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+
+		msg, _ := io.ReadAll(resp.Body)
+
+		return nil, fmt.Errorf("%s (%s)", strings.TrimSpace(string(msg)), resp.Status)
+	}
+
+	return resp.Body, nil
+}
+
+func init() {
+	rootCmd.AddCommand(coordinatorCmd)
+	coordinatorCmd.AddCommand(coordinatorJSONCmd)
+
+	initCoordinatorFlags()
+}
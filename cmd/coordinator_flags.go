@@ -0,0 +1,10 @@
+package cmd
+
+var workers []string
+
+func initCoordinatorFlags() {
+	coordinatorCmd.PersistentFlags().StringSliceVar(&workers, "workers", []string{}, "Addresses of the workers (i.e. host1:7777,host2:7777).")
+	coordinatorCmd.PersistentFlags().StringVar(&workerToken, "token", "", "Secret token of the workers.")
+
+	initSharedFlags(coordinatorJSONCmd)
+}
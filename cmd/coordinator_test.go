@@ -0,0 +1,259 @@
+package cmd
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const testToken = "test-token"
+
+func startTestWorkers(n int) ([]string, func()) {
+	addresses := make([]string, 0, n)
+	servers := make([]*httptest.Server, 0, n)
+
+	for i := 0; i < n; i++ {
+		server := httptest.NewServer(newWorkerHandler(testToken))
+		servers = append(servers, server)
+		addresses = append(addresses, strings.TrimPrefix(server.URL, "http://"))
+	}
+
+	return addresses, func() {
+		for _, server := range servers {
+			server.Close()
+		}
+	}
+}
+
+// startWorkerProcess runs the worker command in a new process of the test
+// binary on a free loopback port and returns its address.
+func startWorkerProcess(t *testing.T) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	address := l.Addr().String()
+	l.Close()
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestWorkerProcesses$")
+	cmd.Env = append(os.Environ(), "WORKER_TEST="+address)
+
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("Could not start the worker: %v", err)
+	}
+
+	t.Cleanup(func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	})
+
+	for i := 0; i < 100; i++ {
+		if conn, err := net.Dial("tcp", address); err == nil {
+			conn.Close()
+			return address
+		}
+
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	t.Fatalf("Worker did not listen on %s", address)
+
+	return ""
+}
+
+// TestWorkerProcesses runs a benchmark on worker commands running in their own
+// processes like they do on other machines.
+func TestWorkerProcesses(t *testing.T) {
+	if address := os.Getenv("WORKER_TEST"); address != "" {
+		rootCmd.SetArgs([]string{"worker", "--listen", address, "--token", testToken})
+		rootCmd.Execute()
+		return
+	}
+
+	setSharedVars()
+
+	var received int32
+
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&received, 1)
+	}))
+	defer target.Close()
+
+	workerAddresses := []string{startWorkerProcess(t), startWorkerProcess(t)}
+
+	config := &WorkerConfig{
+		Config: &JSONConfig{
+			Host:        target.URL,
+			Concurrency: 2,
+			Requests:    5,
+			Paths:       []*PathConfig{{Path: "/"}},
+		},
+	}
+
+	result, err := coordinate(context.Background(), workerAddresses, testToken, config)
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if received := atomic.LoadInt32(&received); received != 10 || result.TotalRequests != 10 || result.SuccessfulRequests != 10 {
+		t.Errorf("Expected 10 requests but got %d and %+v", received, result)
+	}
+}
+
+func TestCoordinate(t *testing.T) {
+	setSharedVars()
+
+	var received int32
+
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&received, 1)
+	}))
+	defer target.Close()
+
+	workerAddresses, closeWorkers := startTestWorkers(3)
+	defer closeWorkers()
+
+	config := &WorkerConfig{
+		Config: &JSONConfig{
+			Host:        target.URL,
+			Concurrency: 2,
+			Requests:    5,
+			Paths:       []*PathConfig{{Path: "/"}, {Path: "/api"}},
+		},
+		TimeSeriesBucket: time.Second,
+	}
+
+	result, err := coordinate(context.Background(), workerAddresses, testToken, config)
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if received := atomic.LoadInt32(&received); received != 30 || result.TotalRequests != 30 || result.SuccessfulRequests != 30 {
+		t.Errorf("Expected 30 requests but got %d and %+v", received, result)
+	}
+
+	if result.ResponseStatusCode[target.URL+"/api"][200] != 15 || result.Histogram.TotalCount != 30 {
+		t.Errorf("Unexpected merged result: %v %d", result.ResponseStatusCode, result.Histogram.TotalCount)
+	}
+
+	if result.TimeSeriesInterval != time.Second || len(result.TimeSeries[target.URL+"/"]) == 0 {
+		t.Errorf("Expected the time series of the workers but got %s %v", result.TimeSeriesInterval, result.TimeSeries)
+	}
+}
+
+func TestCoordinateWrongConfig(t *testing.T) {
+	setSharedVars()
+
+	workerAddresses, closeWorkers := startTestWorkers(1)
+	defer closeWorkers()
+
+	config := &WorkerConfig{Config: &JSONConfig{Paths: []*PathConfig{{Path: "/"}}}}
+	expected := "Could not prepare worker " + workerAddresses[0] + ": No host is provided (400 Bad Request)"
+
+	if _, err := coordinate(context.Background(), workerAddresses, testToken, config); err == nil || err.Error() != expected {
+		t.Errorf("Expected %q but got %v", expected, err)
+	}
+}
+
+func TestWorkerNotPrepared(t *testing.T) {
+	workerAddresses, closeWorkers := startTestWorkers(1)
+	defer closeWorkers()
+
+	expected := "No benchmark is prepared (409 Conflict)"
+
+	if _, err := startWorker(context.Background(), workerAddresses[0], testToken); err == nil || err.Error() != expected {
+		t.Errorf("Expected %q but got %v", expected, err)
+	}
+}
+
+func TestCoordinateUnreachableWorker(t *testing.T) {
+	setSharedVars()
+
+	workerAddresses, closeWorkers := startTestWorkers(1)
+	closeWorkers()
+
+	config := &WorkerConfig{Config: &JSONConfig{Host: "http://localhost", Paths: []*PathConfig{{Path: "/"}}}}
+
+	if _, err := coordinate(context.Background(), workerAddresses, testToken, config); err == nil || !strings.HasPrefix(err.Error(), "Could not prepare worker "+workerAddresses[0]) {
+		t.Errorf("Expected an error of the unreachable worker but got %v", err)
+	}
+}
+
+func TestWorkerWrongToken(t *testing.T) {
+	setSharedVars()
+
+	workerAddresses, closeWorkers := startTestWorkers(1)
+	defer closeWorkers()
+
+	config := &WorkerConfig{Config: &JSONConfig{Host: "http://localhost", Paths: []*PathConfig{{Path: "/"}}}}
+	expected := "Could not prepare worker " + workerAddresses[0] + ": Invalid token (401 Unauthorized)"
+
+	if _, err := coordinate(context.Background(), workerAddresses, "wrong-token", config); err == nil || err.Error() != expected {
+		t.Errorf("Expected %q but got %v", expected, err)
+	}
+
+	if _, err := startWorker(context.Background(), workerAddresses[0], ""); err == nil || !strings.HasPrefix(err.Error(), "Invalid token") {
+		t.Errorf("Expected an invalid token error but got %v", err)
+	}
+}
+
+func TestCoordinateCanceled(t *testing.T) {
+	setSharedVars()
+
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(10 * time.Millisecond)
+	}))
+	defer target.Close()
+
+	workerAddresses, closeWorkers := startTestWorkers(2)
+	defer closeWorkers()
+
+	config := &WorkerConfig{
+		Config: &JSONConfig{
+			Host:        target.URL,
+			Concurrency: 2,
+			Duration:    time.Minute,
+			Paths:       []*PathConfig{{Path: "/"}},
+		},
+	}
+
+	ctx, cancelFunc := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancelFunc()
+
+	start := time.Now()
+	result, err := coordinate(ctx, workerAddresses, testToken, config)
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if time.Since(start) > 10*time.Second {
+		t.Errorf("Expected the workers to stop but the benchmark took %s", time.Since(start))
+	}
+
+	if result.TotalRequests == 0 || len(result.URLs) != 1 {
+		t.Errorf("Expected the partial reports of the workers but got %+v", result)
+	}
+}
+
+func TestStopWorkerNotPrepared(t *testing.T) {
+	workerAddresses, closeWorkers := startTestWorkers(1)
+	defer closeWorkers()
+
+	expected := "No benchmark is prepared (409 Conflict)"
+
+	if _, err := callWorker(context.Background(), workerAddresses[0], testToken, "/stop", nil); err == nil || err.Error() != expected {
+		t.Errorf("Expected %q but got %v", expected, err)
+	}
+}
@@ -1,8 +1,10 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/sasanrose/gbench/bench"
 	"github.com/spf13/cobra"
@@ -15,7 +17,12 @@ var execCmd = &cobra.Command{
 Sample usage:
 
 gbench exec -r 100 -c 10 www.google.com
-gbench exec -X post -d "search=gbench" -r 100 -c 10 www.google.com`,
+gbench exec --duration 30s -c 10 www.google.com
+gbench exec --duration 30s --rate 500/s --max-in-flight 200 www.google.com
+gbench exec --stage ramp=2m:10-200 --stage hold=5m:200 --stage spike=30s:500 www.google.com
+gbench exec -X post -d "search=gbench" -r 100 -c 10 www.google.com
+gbench exec -X post --data-binary @body.json -r 100 -c 10 www.google.com
+gbench exec -X post --form name=gbench --form 'file=@./image.png;type=image/png' www.google.com`,
 	Run: runExec,
 }
 
@@ -25,6 +32,10 @@ func runExec(cmd *cobra.Command, args []string) {
 		os.Exit(2)
 	}
 
+	if (duration > 0 || len(stages) > 0) && !cmd.Flags().Changed("total-requests") {
+		requests = 0
+	}
+
 	configurations, err := getExecConfig(args[0])
 
 	if err != nil {
@@ -37,7 +48,29 @@ func runExec(cmd *cobra.Command, args []string) {
 func getExecConfig(url string) ([]func(*bench.Bench), error) {
 	configurations := make([]func(*bench.Bench), 0)
 
-	urlConfig, err := bench.WithURLSettings(url, method, data, []string{}, "", "")
+	urlConfigurations, err := getBodyConfig(body, contentType, templateBodyFile)
+
+	if err != nil {
+		return []func(*bench.Bench){}, fmt.Errorf("Error with body: %v", err)
+	}
+
+	partConfigurations, err := getPartConfig(form)
+
+	if err != nil {
+		return []func(*bench.Bench){}, fmt.Errorf("Error with form: %v", err)
+	}
+
+	urlConfigurations = append(urlConfigurations, partConfigurations...)
+
+	assertionConfigurations, err := getAssertionConfig(asserts)
+
+	if err != nil {
+		return []func(*bench.Bench){}, fmt.Errorf("Error with assertion: %v", err)
+	}
+
+	urlConfigurations = append(urlConfigurations, assertionConfigurations...)
+
+	urlConfig, err := bench.WithURLSettings(url, method, data, []string{}, "", "", urlConfigurations...)
 
 	if err != nil {
 		return []func(*bench.Bench){}, fmt.Errorf("Error with url: %v", err)
@@ -45,6 +78,82 @@ func getExecConfig(url string) ([]func(*bench.Bench), error) {
 
 	configurations = append(configurations, urlConfig)
 
+	if len(stages) > 0 && rate != "" {
+		return []func(*bench.Bench){}, errors.New("Stages can not be used with a rate")
+	}
+
+	for _, stage := range stages {
+		stageConfig, err := bench.WithStageString(stage)
+
+		if err != nil {
+			return []func(*bench.Bench){}, fmt.Errorf("Error with stage: %v", err)
+		}
+
+		configurations = append(configurations, stageConfig)
+	}
+
+	return configurations, nil
+}
+
+// getBodyConfig returns the URL configs to send the given raw body. A body
+// starting with @ is read from a file whose template expressions are only
+// evaluated when templateFile is set.
+func getBodyConfig(body, contentType string, templateFile bool) ([]func(*bench.URL), error) {
+	if body == "" {
+		return []func(*bench.URL){}, nil
+	}
+
+	if templateFile && strings.HasPrefix(body, "@") {
+		bodyConfig, err := bench.WithBodyFile(body[1:], contentType, true)
+
+		if err != nil {
+			return []func(*bench.URL){}, err
+		}
+
+		return []func(*bench.URL){bodyConfig}, nil
+	}
+
+	bodyConfig, err := bench.WithBodyString(body, contentType)
+
+	if err != nil {
+		return []func(*bench.URL){}, err
+	}
+
+	return []func(*bench.URL){bodyConfig}, nil
+}
+
+// getPartConfig returns the URL configs to send the given multipart parts.
+func getPartConfig(parts []string) ([]func(*bench.URL), error) {
+	configurations := make([]func(*bench.URL), 0, len(parts))
+
+	for _, part := range parts {
+		partConfig, err := bench.WithPartString(part)
+
+		if err != nil {
+			return []func(*bench.URL){}, err
+		}
+
+		configurations = append(configurations, partConfig)
+	}
+
+	return configurations, nil
+}
+
+// getAssertionConfig returns the URL configs to check the responses using the
+// given assertions.
+func getAssertionConfig(assertions []string) ([]func(*bench.URL), error) {
+	configurations := make([]func(*bench.URL), 0, len(assertions))
+
+	for _, assertion := range assertions {
+		assertionConfig, err := bench.WithAssertionString(assertion)
+
+		if err != nil {
+			return []func(*bench.URL){}, err
+		}
+
+		configurations = append(configurations, assertionConfig)
+	}
+
 	return configurations, nil
 }
 
@@ -1,8 +1,11 @@
 package cmd
 
+import "github.com/sasanrose/gbench/bench"
+
 var (
-	data   []string
-	method string
+	data, stages, form, asserts []string
+	method, body, contentType   string
+	templateBodyFile            bool
 )
 
 func initExecFlags() {
@@ -10,6 +13,11 @@ func initExecFlags() {
 
 	execCmd.Flags().IntVarP(&concurrency, "concurrency", "c", defaultConcurreny, "Number of concurrent requests.")
 	execCmd.Flags().IntVarP(&requests, "total-requests", "r", defaultRequests, "Number of total requests to send.")
+	execCmd.Flags().DurationVar(&duration, "duration", 0, "Duration of the benchmark (0 means no duration). Without --total-requests, requests are sent until the duration is elapsed.")
+	execCmd.Flags().StringVar(&rate, "rate", "", "Send requests at a constant rate regardless of the in-flight requests (i.e. 500/s, 30/m or 10/100ms). Concurrency is ignored when a rate is set.")
+	execCmd.Flags().DurationVar(&expectedInterval, "expected-interval", 0, "Expected interval between requests of a worker. When set, the report also contains response times corrected for coordinated omission.")
+	execCmd.Flags().StringSliceVar(&stages, "stage", []string{}, "Load stage in the format of '[name=]duration:from[-to]' (i.e. 'ramp=2m:10-200' or '5m:200'). Concurrency changes linearly from 'from' to 'to' during the stage. This can be used multiple times and the benchmark stops after the last stage.")
+	execCmd.Flags().IntVar(&maxInFlight, "max-in-flight", 0, "Maximum number of in-flight requests when a rate is set. Requests exceeding it are dropped (0 means 1000).")
 	execCmd.Flags().IntSliceVarP(&successStatusCodes,
 		"status-codes",
 		"s",
@@ -20,8 +28,31 @@ The user name and passwords are split up on the first colon, as a result it is i
 	execCmd.Flags().StringVar(&proxyURL, "proxy", "", "HTTP proxy.")
 	execCmd.Flags().DurationVarP(&connectionTimeout, "connect-timeout", "", 0, "Connection timeout (0 means no timeout).")
 	execCmd.Flags().DurationVarP(&responseTimeout, "response-timeout", "", 0, "Response timeout (0 means no timeout).")
+	execCmd.Flags().BoolVar(&disableKeepAlives, "no-keepalive", false, "Open a new connection for every request instead of reusing idle ones.")
+	execCmd.Flags().IntVar(&maxIdleConnsPerHost, "max-idle-conns-per-host", 0, "Maximum number of idle connections kept per host (0 means 2).")
+	execCmd.Flags().IntVar(&maxConnsPerHost, "max-conns-per-host", 0, "Maximum number of connections per host. Requests exceeding it wait for a connection (0 means no limit).")
+	execCmd.Flags().DurationVar(&idleConnTimeout, "idle-conn-timeout", 0, "Time after which idle connections are closed (0 means no timeout).")
+	execCmd.Flags().BoolVar(&disableRedirects, "no-redirect", false, "Do not follow redirects. Redirect responses are reported with their own status code (i.e. use -s 302 to count them as successful).")
+	execCmd.Flags().IntVar(&maxRedirects, "max-redirects", 0, "Maximum number of redirects to follow. Requests exceeding it fail (0 means 10).")
 	execCmd.Flags().StringVarP(&method, "request", "X", defaultMethod, "Specify a custom HTTP method.")
 	execCmd.Flags().StringSliceVarP(&headers, "header", "H", []string{}, "HTTP header in format of 'key: value' or 'key: value;' or 'key;'. This can be used multiple times.")
 	execCmd.Flags().StringSliceVarP(&data, "data", "d", []string{}, "Sends the specified data in a request. The format should be 'key=val' or 'key1=val1&key2=val2'. This can be used multiple times.")
+	execCmd.Flags().StringVar(&body, "data-binary", "", "Sends the specified raw body in a request. Use '@path' to read the body from a file. This can not be used along with --data.")
+	execCmd.Flags().BoolVar(&templateBodyFile, "template-body-file", false, "Evaluate the template expressions of a body read from a file. Bodies read from files are sent as is by default.")
+	execCmd.Flags().StringArrayVar(&form, "form", []string{}, "Sends a multipart/form-data part in the format of 'name=value' or 'name=@path[;type=content/type][;filename=name]' (i.e. 'file=@./image.png;type=image/png'). Files are streamed for each request. This can be used multiple times.")
+	execCmd.Flags().StringArrayVar(&asserts, "assert", []string{}, "Response assertion in the format of 'type:expression'. Accepted types are 'body-contains', 'body-regex', 'json' (i.e. 'json:data.status=ok'), 'header' (i.e. 'header:Content-Type: application/json'), 'body-size' (i.e. 'body-size:10-2048') and 'max-latency' (i.e. 'max-latency:200ms'). Responses failing an assertion are counted as failed. This can be used multiple times.")
+	execCmd.Flags().StringVar(&contentType, "content-type", "", "Content type of the raw body (Default is application/json for JSON bodies and application/octet-stream otherwise).")
+	execCmd.Flags().StringVar(&feederPath, "feeder", "", "Path of a CSV or JSONL file whose rows feed the {{feed column}} template expressions.")
+	execCmd.Flags().StringVar(&feederMode, "feeder-mode", bench.FeederSequential, "Mode of picking the rows of the feeder. Accepted values are 'sequential', 'random' and 'once' (The benchmark stops when all the rows are used).")
+	execCmd.Flags().StringVar(&caCertPath, "cacert", "", "Path of a PEM file of CA certificates to verify the servers instead of the system ones.")
+	execCmd.Flags().StringVar(&clientCertPath, "cert", "", "Path of a PEM client certificate file for mutual TLS. It should be used along with --key.")
+	execCmd.Flags().StringVar(&clientKeyPath, "key", "", "Path of the PEM private key file of the client certificate.")
+	execCmd.Flags().BoolVarP(&insecure, "insecure", "k", false, "Accept any certificate of the servers (i.e. self-signed certificates).")
+	execCmd.Flags().StringVar(&tlsServerName, "tls-server-name", "", "Server name to send as SNI and to verify the certificates of the servers with.")
+	execCmd.Flags().StringVar(&tlsMinVersion, "tls-min-version", "", "Minimum TLS version. Accepted values are 1.0, 1.1, 1.2 and 1.3.")
+	execCmd.Flags().StringVar(&tlsMaxVersion, "tls-max-version", "", "Maximum TLS version. Accepted values are 1.0, 1.1, 1.2 and 1.3.")
+	execCmd.Flags().StringSliceVar(&cipherSuites, "ciphers", []string{}, "TLS 1.0-1.2 cipher suites to use (i.e. TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256). This can be used multiple times.")
+	execCmd.Flags().StringVar(&protocol, "protocol", bench.ProtocolHTTP1, "Protocol of the requests. Accepted values are 'http1', 'http2' (HTTP/2 over TLS) and 'h2c' (HTTP/2 without TLS with prior knowledge).")
+	execCmd.Flags().IntVar(&streamsPerConnection, "streams-per-connection", 0, "Number of concurrent streams per HTTP/2 connection. Every group of that many workers shares a connection of its own (0 means all the workers share the connections).")
 	execCmd.Flags().StringVarP(&rawCookie, "cookie", "b", "", "A string to be sent as raw cookie (In the format of Set-Cookie HTTP header).")
 }
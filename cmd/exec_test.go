@@ -1,9 +1,12 @@
 package cmd
 
 import (
+	"io/ioutil"
 	"net/http"
 	"os"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/sasanrose/gbench/bench"
 )
@@ -57,6 +60,161 @@ func TestUrlError(t *testing.T) {
 	}
 }
 
+func TestExecStages(t *testing.T) {
+	method = http.MethodGet
+	data = []string{}
+	rate = ""
+	stages = []string{"ramp=1m:1-10", "30s:10"}
+
+	defer func() {
+		stages = []string{}
+	}()
+
+	configurations, err := getExecConfig("http://url")
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	b := bench.NewBench(configurations...)
+
+	if len(b.Stages) != 2 || b.Stages[0].Name != "ramp" || b.Stages[1].Duration != 30*time.Second {
+		t.Errorf("Unexpected stages: %+v", b.Stages)
+	}
+
+	stages = []string{"wrong"}
+
+	if _, err := getExecConfig("http://url"); err == nil || err.Error() != "Error with stage: Wrong stage format: wrong" {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	stages = []string{"1m:1"}
+	rate = "10/s"
+
+	defer func() {
+		rate = ""
+	}()
+
+	if _, err := getExecConfig("http://url"); err == nil || err.Error() != "Stages can not be used with a rate" {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestExecBody(t *testing.T) {
+	method = http.MethodPost
+	data = []string{}
+	body = `{"key": "val"}`
+	contentType = "application/vnd.api+json"
+
+	defer func() {
+		body = ""
+		contentType = ""
+	}()
+
+	configurations, err := getExecConfig("http://url")
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	b := bench.NewBench(configurations...)
+
+	if string(b.URLs[0].Body) != body || b.URLs[0].ContentType != contentType {
+		t.Errorf("Unexpected body %q with content type %q", b.URLs[0].Body, b.URLs[0].ContentType)
+	}
+
+	body = "@/non/existent/file"
+
+	if _, err := getExecConfig("http://url"); err == nil || !strings.HasPrefix(err.Error(), "Error with body: Could not read the body file") {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	file, err := ioutil.TempFile("", "gbench-body")
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	defer os.Remove(file.Name())
+
+	file.WriteString("id={{seq}}")
+	file.Close()
+
+	body = "@" + file.Name()
+
+	defer func() {
+		templateBodyFile = false
+	}()
+
+	for _, templateBodyFile = range []bool{false, true} {
+		configurations, err := getExecConfig("http://url")
+
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if u := bench.NewBench(configurations...).URLs[0]; string(u.Body) != "id={{seq}}" || u.RawBody == templateBodyFile {
+			t.Errorf("Unexpected body %q (raw %v) when templating the body file is %v", u.Body, u.RawBody, templateBodyFile)
+		}
+	}
+}
+
+func TestExecForm(t *testing.T) {
+	method = http.MethodPost
+	data = []string{}
+	form = []string{"name=gbench", "empty="}
+
+	defer func() {
+		form = []string{}
+	}()
+
+	configurations, err := getExecConfig("http://url")
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	b := bench.NewBench(configurations...)
+
+	if len(b.URLs[0].Parts) != 2 || b.URLs[0].Parts[0].Name != "name" || b.URLs[0].Parts[0].Value != "gbench" {
+		t.Errorf("Unexpected parts: %+v", b.URLs[0].Parts)
+	}
+
+	form = []string{"wrong"}
+
+	if _, err := getExecConfig("http://url"); err == nil || err.Error() != "Error with form: Wrong part format: wrong" {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestExecAssertions(t *testing.T) {
+	method = http.MethodGet
+	data = []string{}
+	asserts = []string{"json:status=ok", "max-latency:200ms"}
+
+	defer func() {
+		asserts = []string{}
+	}()
+
+	configurations, err := getExecConfig("http://url")
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	b := bench.NewBench(configurations...)
+
+	if len(b.URLs[0].Assertions) != 2 || b.URLs[0].Assertions[0].Type != bench.AssertJSON || b.URLs[0].Assertions[1].Expression != "200ms" {
+		t.Errorf("Unexpected assertions: %+v", b.URLs[0].Assertions)
+	}
+
+	asserts = []string{"max-latency:fast"}
+
+	if _, err := getExecConfig("http://url"); err == nil || err.Error() != "Error with assertion: Wrong max latency: fast" {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
 func TestNoUrl(t *testing.T) {
 	if os.Getenv("CRASH_TEST") == "1" {
 		runExec(execCmd, []string{})
@@ -37,10 +37,22 @@ func runJSON(cmd *cobra.Command, args []string) {
 }
 
 func getJSONConfig(filePath string) ([]func(*bench.Bench), error) {
+	config, err := readJSONConfig(filePath)
+
+	if err != nil {
+		return []func(*bench.Bench){}, err
+	}
+
+	thresholds = append(thresholds, config.Thresholds...)
+
+	return getJSONConfigurations(config)
+}
+
+func readJSONConfig(filePath string) (*JSONConfig, error) {
 	file, err := fs.Open(filePath)
 
 	if err != nil {
-		return []func(*bench.Bench){}, fmt.Errorf("Could not open %q: %v", filePath, err)
+		return nil, fmt.Errorf("Could not open %q: %v", filePath, err)
 	}
 
 	defer file.Close()
@@ -50,11 +62,17 @@ func getJSONConfig(filePath string) ([]func(*bench.Bench), error) {
 
 	decoder.Decode(config)
 
+	return config, nil
+}
+
+// getJSONConfigurations returns the configurations of the benchmark of a JSON
+// configuration and sets the global ones.
+func getJSONConfigurations(config *JSONConfig) ([]func(*bench.Bench), error) {
 	if config.Host == "" {
 		return []func(*bench.Bench){}, errors.New("No host is provided")
 	}
 
-	if len(config.Paths) == 0 {
+	if len(config.Paths) == 0 && len(config.Scenarios) == 0 {
 		return []func(*bench.Bench){}, errors.New("No path is provided")
 	}
 
@@ -63,19 +81,46 @@ func getJSONConfig(filePath string) ([]func(*bench.Bench), error) {
 	configurations := make([]func(*bench.Bench), 0)
 
 	for _, path := range config.Paths {
-		URL := config.Host + "/" + strings.TrimLeft(path.Path, "/")
-		urlConfig, err := bench.WithURLSettings(URL,
-			path.Method,
-			path.Data,
-			path.Headers,
-			path.RawCookie,
-			path.AuthUserPass)
+		u, err := getPathURL(config.Host, path)
 
 		if err != nil {
-			return []func(*bench.Bench){}, fmt.Errorf("Error with url: %v", err)
+			return []func(*bench.Bench){}, err
 		}
 
-		configurations = append(configurations, urlConfig)
+		configurations = append(configurations, bench.WithURL(u))
+	}
+
+	for _, scenario := range config.Scenarios {
+		s, err := getScenario(config.Host, scenario)
+
+		if err != nil {
+			return []func(*bench.Bench){}, err
+		}
+
+		configurations = append(configurations, bench.WithScenario(s))
+	}
+
+	if len(config.Stages) > 0 && config.Rate != "" {
+		return []func(*bench.Bench){}, errors.New("Stages can not be used with a rate")
+	}
+
+	for _, stage := range config.Stages {
+		if stage.Duration <= 0 {
+			return []func(*bench.Bench){}, errors.New("Stage duration should be greater than zero")
+		}
+
+		to := stage.From
+
+		if stage.To != nil {
+			to = *stage.To
+		}
+
+		configurations = append(configurations, bench.WithStage(&bench.Stage{
+			Name:     stage.Name,
+			Duration: stage.Duration,
+			From:     stage.From,
+			To:       to,
+		}))
 	}
 
 	if len(config.StatusCodes) == 0 {
@@ -86,23 +131,133 @@ func getJSONConfig(filePath string) ([]func(*bench.Bench), error) {
 		config.Concurrency = defaultConcurreny
 	}
 
-	if config.Requests == 0 {
+	if config.Requests == 0 && config.Duration == 0 && len(config.Stages) == 0 {
 		config.Requests = defaultRequests
 	}
 
 	successStatusCodes = config.StatusCodes
 	concurrency = config.Concurrency
 	requests = config.Requests
+	duration = config.Duration
+	rate = config.Rate
+	maxInFlight = config.MaxInFlight
+	expectedInterval = config.ExpectedInterval
 	headers = config.Headers
 	authUserPass = config.AuthUserPass
 	proxyURL = config.Proxy
 	rawCookie = config.RawCookie
 	connectionTimeout = config.ConnectTimeout
 	responseTimeout = config.ResponseTimeout
+	disableKeepAlives = config.NoKeepAlive
+	maxIdleConnsPerHost, maxConnsPerHost = config.MaxIdleConns, config.MaxConns
+	idleConnTimeout = config.IdleConnTimeout
+	disableRedirects, maxRedirects = config.NoRedirect, config.MaxRedirects
+	feederPath, feederMode = "", ""
+
+	if config.Feeder != nil {
+		feederPath, feederMode = config.Feeder.Path, config.Feeder.Mode
+	}
+
+	if config.TLS == nil {
+		config.TLS = &TLSConfig{}
+	}
+
+	caCertPath = config.TLS.CACert
+	clientCertPath, clientKeyPath = config.TLS.Cert, config.TLS.Key
+	insecure = config.TLS.Insecure
+	tlsServerName = config.TLS.ServerName
+	tlsMinVersion, tlsMaxVersion = config.TLS.MinVersion, config.TLS.MaxVersion
+	cipherSuites = config.TLS.CipherSuites
+	protocol, streamsPerConnection = config.Protocol, config.Streams
 
 	return configurations, nil
 }
 
+// getPathURL creates the endpoint of a path of the host.
+func getPathURL(host string, path *PathConfig) (*bench.URL, error) {
+	URL := host + "/" + strings.TrimLeft(path.Path, "/")
+	urlConfigurations, err := getPathBodyConfig(path)
+
+	if err != nil {
+		return nil, fmt.Errorf("Error with body: %v", err)
+	}
+
+	partConfigurations, err := getPartConfig(path.Form)
+
+	if err != nil {
+		return nil, fmt.Errorf("Error with form: %v", err)
+	}
+
+	urlConfigurations = append(urlConfigurations, partConfigurations...)
+
+	assertionConfigurations, err := getAssertionConfig(path.Assert)
+
+	if err != nil {
+		return nil, fmt.Errorf("Error with assertion: %v", err)
+	}
+
+	urlConfigurations = append(urlConfigurations, assertionConfigurations...)
+
+	u, err := bench.NewURL(URL,
+		path.Method,
+		path.Data,
+		path.Headers,
+		path.RawCookie,
+		path.AuthUserPass,
+		urlConfigurations...)
+
+	if err != nil {
+		return nil, fmt.Errorf("Error with url: %v", err)
+	}
+
+	return u, nil
+}
+
+// getScenario creates a scenario whose steps are paths of the host.
+func getScenario(host string, config *ScenarioConfig) (*bench.Scenario, error) {
+	scenario := &bench.Scenario{Name: config.Name}
+
+	for _, stepConfig := range config.Steps {
+		u, err := getPathURL(host, &stepConfig.PathConfig)
+
+		if err != nil {
+			return nil, err
+		}
+
+		step := &bench.Step{Name: stepConfig.Name, URL: u}
+
+		for _, extract := range stepConfig.Extract {
+			extraction, err := bench.NewExtraction(extract.Name, extract.Type, extract.Expression)
+
+			if err != nil {
+				return nil, fmt.Errorf("Error with extraction: %v", err)
+			}
+
+			step.Extractions = append(step.Extractions, extraction)
+		}
+
+		scenario.Steps = append(scenario.Steps, step)
+	}
+
+	return scenario, nil
+}
+
+// getPathBodyConfig returns the URL configs to send the body of a path. A JSON
+// string is used as a raw body while any other JSON value is sent as is.
+func getPathBodyConfig(path *PathConfig) ([]func(*bench.URL), error) {
+	if len(path.Body) == 0 || string(path.Body) == "null" {
+		return []func(*bench.URL){}, nil
+	}
+
+	var body string
+
+	if err := json.Unmarshal(path.Body, &body); err == nil {
+		return getBodyConfig(body, path.ContentType, path.TemplateBodyFile)
+	}
+
+	return []func(*bench.URL){bench.WithBody(path.Body, path.ContentType)}, nil
+}
+
 func init() {
 	rootCmd.AddCommand(jsonCmd)
 
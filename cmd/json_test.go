@@ -15,6 +15,10 @@ var testJSON = `{
     "host": "http://localhost:8080",
     "concurrency": 5,
     "requests": 100,
+    "duration": 60000000000,
+    "rate": "500/s",
+    "max-in-flight": 50,
+    "expected-interval": 10000000,
 	"status-codes": [200, 201],
 	"proxy": "test.proxy.url",
 	"user": "user:pass",
@@ -22,6 +26,17 @@ var testJSON = `{
 	"headers": ["X-Custom-Header: TestValue;"],
 	"connect-timeout": 1000000000,
 	"response-timeout": 5000000000,
+	"disable-keep-alives": true,
+	"max-idle-conns-per-host": 10,
+	"max-conns-per-host": 20,
+	"idle-conn-timeout": 60000000000,
+	"disable-redirects": true,
+	"max-redirects": 5,
+	"feeder": {"path": "users.csv", "mode": "random"},
+	"tls": {"ca-cert": "ca.pem", "cert": "cert.pem", "key": "key.pem", "insecure": true, "server-name": "example.com", "min-version": "1.2", "max-version": "1.3", "cipher-suites": ["TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256"]},
+	"protocol": "h2c",
+	"streams-per-connection": 10,
+	"thresholds": ["avg_latency{url=/test} < 200ms", "timeouts == 0"],
 	"paths": [
         {
             "path": "/"
@@ -43,6 +58,61 @@ var testJSONNoHost = `{
         }
     ]
 }`
+var testJSONStages = `{
+	"host": "http://localhost:8080",
+	"stages": [
+		{"name": "ramp", "duration": 1000000000, "from": 1, "to": 5},
+		{"duration": 2000000000, "from": 5},
+		{"name": "down", "duration": 1000000000, "from": 5, "to": 0}
+	],
+	"paths": [{"path": "/"}]
+}`
+
+var testJSONStagesWithRate = `{
+	"host": "http://localhost:8080",
+	"rate": "10/s",
+	"stages": [{"duration": 1000000000, "from": 1}],
+	"paths": [{"path": "/"}]
+}`
+
+var testJSONBody = `{
+	"host": "http://localhost:8080",
+	"paths": [
+		{"path": "/object", "method": "post", "body": {"key": "val"}},
+		{"path": "/string", "method": "post", "body": "raw", "content-type": "text/plain"},
+		{"path": "/empty"},
+		{"path": "/form", "method": "post", "form": ["name=gbench"]},
+		{"path": "/assert", "assert": ["body-contains:ok"]}
+	]
+}`
+
+var testJSONScenarios = `{
+	"host": "http://localhost:8080",
+	"scenarios": [
+		{
+			"name": "checkout",
+			"steps": [
+				{
+					"name": "login",
+					"path": "/login",
+					"method": "post",
+					"body": {"user": "gbench"},
+					"extract": [
+						{"name": "token", "type": "json", "expression": "data.token"},
+						{"name": "session", "type": "cookie", "expression": "session"}
+					]
+				},
+				{"path": "/orders", "headers": ["Authorization: Bearer {{var token}}"]}
+			]
+		}
+	]
+}`
+
+var testJSONWrongExtraction = `{
+	"host": "http://localhost:8080",
+	"scenarios": [{"steps": [{"path": "/", "extract": [{"name": "token", "type": "xpath", "expression": "a"}]}]}]
+}`
+
 var testJSONNoPath = `{
     "concurrency": 5,
     "requests": 100,
@@ -60,6 +130,7 @@ func TestJSONConfig(t *testing.T) {
 
 	mockedFile := &mockedFileType{bytes.NewBufferString(testJSON)}
 	mfs.file = mockedFile
+	thresholds = []string{"success_rate > 99%"}
 
 	configurations, err := getJSONConfig("testfile")
 
@@ -83,6 +154,18 @@ func TestJSONConfig(t *testing.T) {
 		t.Error("Unexpected requests")
 	}
 
+	if duration != time.Minute {
+		t.Error("Unexpected duration")
+	}
+
+	if rate != "500/s" || maxInFlight != 50 {
+		t.Error("Unexpected rate")
+	}
+
+	if expectedInterval != 10*time.Millisecond {
+		t.Error("Unexpected expected interval")
+	}
+
 	if authUserPass != "user:pass" {
 		t.Error("Unexpected userpass")
 	}
@@ -103,6 +186,34 @@ func TestJSONConfig(t *testing.T) {
 		t.Error("Unecxpected timeouts")
 	}
 
+	if feederPath != "users.csv" || feederMode != "random" {
+		t.Errorf("Unexpected feeder: %s %s", feederPath, feederMode)
+	}
+
+	if caCertPath != "ca.pem" || clientCertPath != "cert.pem" || clientKeyPath != "key.pem" || !insecure || tlsServerName != "example.com" {
+		t.Error("Unexpected TLS certificates")
+	}
+
+	if tlsMinVersion != "1.2" || tlsMaxVersion != "1.3" || len(cipherSuites) != 1 {
+		t.Error("Unexpected TLS versions and cipher suites")
+	}
+
+	if !disableKeepAlives || maxIdleConnsPerHost != 10 || maxConnsPerHost != 20 || idleConnTimeout != time.Minute {
+		t.Error("Unexpected connection settings")
+	}
+
+	if !disableRedirects || maxRedirects != 5 {
+		t.Error("Unexpected redirect settings")
+	}
+
+	if protocol != bench.ProtocolH2C || streamsPerConnection != 10 {
+		t.Errorf("Unexpected protocol settings: %s %d", protocol, streamsPerConnection)
+	}
+
+	if len(thresholds) != 3 || thresholds[0] != "success_rate > 99%" || thresholds[2] != "timeouts == 0" {
+		t.Errorf("Unexpected thresholds: %v", thresholds)
+	}
+
 	b := bench.NewBench(configurations...)
 
 	checkBench(b, t)
@@ -126,6 +237,125 @@ func checkBench(b *bench.Bench, t *testing.T) {
 	}
 }
 
+func TestJSONStages(t *testing.T) {
+	oldFs := fs
+	mfs := &mockedFSType{}
+	fs = mfs
+
+	defer func() {
+		fs = oldFs
+	}()
+
+	mfs.file = &mockedFileType{bytes.NewBufferString(testJSONStages)}
+
+	configurations, err := getJSONConfig("testfile")
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if requests != 0 {
+		t.Errorf("Expected no request limit with stages but got %d", requests)
+	}
+
+	b := bench.NewBench(configurations...)
+
+	if len(b.Stages) != 3 ||
+		*b.Stages[0] != (bench.Stage{Name: "ramp", Duration: time.Second, From: 1, To: 5}) ||
+		*b.Stages[1] != (bench.Stage{Name: "stage 2", Duration: 2 * time.Second, From: 5, To: 5}) ||
+		*b.Stages[2] != (bench.Stage{Name: "down", Duration: time.Second, From: 5, To: 0}) {
+		t.Errorf("Unexpected stages: %+v", b.Stages)
+	}
+}
+
+func TestJSONBody(t *testing.T) {
+	oldFs := fs
+	mfs := &mockedFSType{}
+	fs = mfs
+
+	defer func() {
+		fs = oldFs
+	}()
+
+	mfs.file = &mockedFileType{bytes.NewBufferString(testJSONBody)}
+
+	configurations, err := getJSONConfig("testfile")
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	b := bench.NewBench(configurations...)
+
+	if string(b.URLs[0].Body) != `{"key": "val"}` || b.URLs[0].ContentType != "application/json" {
+		t.Errorf("Unexpected body %q with content type %q", b.URLs[0].Body, b.URLs[0].ContentType)
+	}
+
+	if string(b.URLs[1].Body) != "raw" || b.URLs[1].ContentType != "text/plain" {
+		t.Errorf("Unexpected body %q with content type %q", b.URLs[1].Body, b.URLs[1].ContentType)
+	}
+
+	if len(b.URLs[2].Body) != 0 {
+		t.Errorf("Did not expect a body but got %q", b.URLs[2].Body)
+	}
+
+	if len(b.URLs[3].Parts) != 1 || *b.URLs[3].Parts[0] != (bench.Part{Name: "name", Value: "gbench"}) {
+		t.Errorf("Unexpected parts: %+v", b.URLs[3].Parts)
+	}
+
+	if len(b.URLs[4].Assertions) != 1 || b.URLs[4].Assertions[0].String() != "body-contains:ok" {
+		t.Errorf("Unexpected assertions: %+v", b.URLs[4].Assertions)
+	}
+}
+
+func TestJSONScenarios(t *testing.T) {
+	oldFs := fs
+	mfs := &mockedFSType{}
+	fs = mfs
+
+	defer func() {
+		fs = oldFs
+	}()
+
+	mfs.file = &mockedFileType{bytes.NewBufferString(testJSONScenarios)}
+
+	configurations, err := getJSONConfig("testfile")
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	b := bench.NewBench(configurations...)
+
+	if len(b.URLs) != 0 || len(b.Scenarios) != 1 || b.Scenarios[0].Name != "checkout" || len(b.Scenarios[0].Steps) != 2 {
+		t.Fatalf("Unexpected scenarios: %+v", b.Scenarios)
+	}
+
+	login, orders := b.Scenarios[0].Steps[0], b.Scenarios[0].Steps[1]
+
+	if login.Name != "login" || login.URL.Addr != "http://localhost:8080/login" || string(login.URL.Body) != `{"user": "gbench"}` {
+		t.Errorf("Unexpected first step: %+v", login.URL)
+	}
+
+	if len(login.Extractions) != 2 || login.Extractions[0].Variable != "token" || login.Extractions[1].Type != bench.ExtractCookie {
+		t.Errorf("Unexpected extractions: %+v", login.Extractions)
+	}
+
+	if orders.Name != "step 2" || orders.URL.Headers["Authorization"] != "Bearer {{var token}}" {
+		t.Errorf("Unexpected second step: %+v", orders.URL)
+	}
+}
+
+func TestJSONWrongExtraction(t *testing.T) {
+	mockedFile := &mockedFileType{bytes.NewBufferString(testJSONWrongExtraction)}
+	testError(t, "Error with extraction: Wrong extraction type of token: xpath", mockedFile, nil)
+}
+
+func TestJSONStagesWithRate(t *testing.T) {
+	mockedFile := &mockedFileType{bytes.NewBufferString(testJSONStagesWithRate)}
+	testError(t, "Stages can not be used with a rate", mockedFile, nil)
+}
+
 func TestWrongFile(t *testing.T) {
 	testError(t, "Could not open \"Test file\": Test error", nil, errors.New("Test error"))
 }
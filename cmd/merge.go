@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/sasanrose/gbench/report"
+	"github.com/spf13/cobra"
+)
+
+var mergeCmd = &cobra.Command{
+	Use:   "merge report1.json report2.json [report3.json...]",
+	Short: "Merge the reports of several benchmarks into one",
+	Long: `Merges the reports generated by exec or json command on several machines or
+shards into one report which can be rendered, compared and checked like the others.
+
+Sample usage:
+gbench merge shard1.json shard2.json
+gbench merge shard1.json shard2.json shard3.json -o ./merged.json -F`,
+	Args: cobra.MinimumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		results := make([]*report.Result, 0, len(args))
+
+		for _, path := range args {
+			results = append(results, readResult(path))
+		}
+
+		merged, err := mergeResults(args, results)
+
+		if err != nil {
+			exitWithError(err.Error())
+		}
+
+		outputFile := createOutputFile()
+
+		defer outputFile.Close()
+
+		log.Printf("Storing the merged report in %s...", outputPath)
+		json.NewEncoder(outputFile).Encode(merged)
+	},
+}
+
+// mergeResults merges named results into a new one.
+func mergeResults(names []string, results []*report.Result) (*report.Result, error) {
+	merged := &report.Result{}
+	merged.Init(0)
+
+	for i, result := range results {
+		if err := merged.Merge(result); err != nil {
+			return nil, fmt.Errorf("Could not merge %s: %v", names[i], err)
+		}
+	}
+
+	return merged, nil
+}
+
+func init() {
+	rootCmd.AddCommand(mergeCmd)
+
+	initMergeFlags()
+}
@@ -0,0 +1,6 @@
+package cmd
+
+func initMergeFlags() {
+	mergeCmd.Flags().BoolVarP(&forceOverWrite, "force", "F", false, "Force overwrite for the merged report file.")
+	mergeCmd.Flags().StringVarP(&outputPath, "output", "o", "./report.json", "The path to store the merged report.")
+}
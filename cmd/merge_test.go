@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sasanrose/gbench/report"
+)
+
+func TestMergeResults(t *testing.T) {
+	results := make([]*report.Result, 0, 2)
+
+	for i := 0; i < 2; i++ {
+		r := &report.Result{}
+		r.Init(1)
+		r.AddSentRequest("http://testurl.com")
+		r.AddResponseTime("http://testurl.com", time.Duration(i+1)*time.Millisecond)
+		r.AddResponseStatusCode("http://testurl.com", 200, false)
+		results = append(results, r)
+	}
+
+	merged, err := mergeResults([]string{"shard1.json", "shard2.json"}, results)
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if merged.TotalRequests != 2 || merged.SuccessfulRequests != 2 || merged.LongestResponseTime != 2*time.Millisecond {
+		t.Errorf("Unexpected merged result: %+v", merged)
+	}
+
+	mismatched := &report.Result{}
+	mismatched.Init(1)
+	mismatched.SetConcurrencyWindow(time.Second)
+	mismatched.SetStartTime(time.Now())
+	mismatched.AddResponseStatusCode("http://testurl.com", 200, false)
+
+	expected := "Could not merge shard3.json: Concurrency windows do not match: 0s and 1s"
+
+	if _, err := mergeResults([]string{"shard1.json", "shard2.json", "shard3.json"}, append(results, mismatched)); err == nil || err.Error() != expected {
+		t.Errorf("Expected %q but got %v", expected, err)
+	}
+}
@@ -3,7 +3,10 @@ package cmd
 import (
 	"encoding/json"
 	"fmt"
+	"net"
 	"os"
+	"strconv"
+	"strings"
 
 	renderer "github.com/sasanrose/gbench/render/driver"
 	"github.com/sasanrose/gbench/report"
@@ -16,8 +19,10 @@ var renderCmd = &cobra.Command{
 	Long: `Sample usage:
 gbench render (Will use all the default values)
 gbench render -i ./path/to/report.json
+gbench render -i ./path/to/report.json --percentiles 50,99,99.9
 gbench render -i ./path/to/report.json --driver html
-gbench render -i ./path/to/report.json --driver html -a 0.0.0.0 -p 7777`,
+gbench render -i ./path/to/report.json --driver html -a 0.0.0.0 -p 7777
+gbench render -i ./path/to/report.json --driver html-file -o ./report.html`,
 	Run: func(cmd *cobra.Command, args []string) {
 		file, err := os.Open(input)
 
@@ -34,32 +39,102 @@ gbench render -i ./path/to/report.json --driver html -a 0.0.0.0 -p 7777`,
 		}
 
 		if driver == "html" {
-			renderHTML(file, cmd)
+			renderHTML(file)
 			return
 		}
 
-		fmt.Fprintf(os.Stderr, "Invalid dirver: %s. Only cli and html are supported.\n", driver)
+		if driver == "html-file" {
+			renderHTMLFile(file)
+			return
+		}
+
+		fmt.Fprintf(os.Stderr, "Invalid dirver: %s. Only cli, html and html-file are supported.\n", driver)
 		cmd.Usage()
 		os.Exit(2)
 	},
 }
 
 func renderCli(file *os.File) {
+	result := decodeResult(file)
+	parsedPercentiles, err := parsePercentiles(percentiles)
+
+	if err != nil {
+		exitWithError(err.Error())
+	}
+
+	r := renderer.NewCli(renderer.WithPercentiles(parsedPercentiles))
+	r.Render(result)
+}
+
+func decodeResult(file *os.File) *report.Result {
 	decoder := json.NewDecoder(file)
 	result := &report.Result{}
 
 	if err := decoder.Decode(result); err != nil {
-		fmt.Fprintf(os.Stderr, "Invalid file %s: %v\n", input, err)
+		fmt.Fprintf(os.Stderr, "Invalid file %s: %v\n", file.Name(), err)
 		os.Exit(2)
 	}
 
-	r := renderer.NewCli()
-	r.Render(result)
+	return result
+}
+
+func parsePercentiles(percentiles []string) ([]float64, error) {
+	parsedPercentiles := make([]float64, 0, len(percentiles))
+
+	for _, percentile := range percentiles {
+		p, err := strconv.ParseFloat(strings.TrimSpace(percentile), 64)
+
+		if err != nil || p <= 0 || p > 100 {
+			return []float64{}, fmt.Errorf("Invalid percentile: %s", percentile)
+		}
+
+		parsedPercentiles = append(parsedPercentiles, p)
+	}
+
+	return parsedPercentiles, nil
 }
 
-func renderHTML(file *os.File, cmd *cobra.Command) {
-	fmt.Fprintf(os.Stderr, "HTML driver is an upcoming feature. Sorry for the inconvenience.\nPlease use cli driver for now.\n")
-	os.Exit(2)
+func renderHTML(file *os.File) {
+	result := decodeResult(file)
+	parsedPercentiles, err := parsePercentiles(percentiles)
+
+	if err != nil {
+		exitWithError(err.Error())
+	}
+
+	r := renderer.NewHTML(renderer.WithAddress(net.JoinHostPort(address, port)),
+		renderer.WithHTMLPercentiles(parsedPercentiles))
+
+	if err := r.Render(result); err != nil {
+		exitWithError(fmt.Sprintf("Could not serve the report: %v\n", err))
+	}
+}
+
+func renderHTMLFile(file *os.File) {
+	result := decodeResult(file)
+	parsedPercentiles, err := parsePercentiles(percentiles)
+
+	if err != nil {
+		exitWithError(err.Error())
+	}
+
+	if _, err := os.Stat(htmlOutputPath); err == nil && !forceOverWrite {
+		exitWithError(fmt.Sprintf("%s already exists. Use -F to overwrite.\n", htmlOutputPath))
+	}
+
+	outputFile, err := os.Create(htmlOutputPath)
+
+	if err != nil {
+		exitWithError(fmt.Sprintf("Could not open %s: %v\n", htmlOutputPath, err))
+	}
+
+	defer outputFile.Close()
+
+	r := renderer.NewHTMLFile(outputFile, renderer.WithHTMLPercentiles(parsedPercentiles))
+
+	if err := r.Render(result); err != nil {
+		exitWithError(fmt.Sprintf("Could not write the report: %v\n", err))
+	}
 }
 
 func init() {
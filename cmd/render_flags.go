@@ -2,11 +2,16 @@ package cmd
 
 var (
 	driver, address, port, input string
+	htmlOutputPath               string
+	percentiles                  []string
 )
 
 func initRenderFlags() {
 	renderCmd.Flags().StringVarP(&input, "input", "i", "./report.json", "Path to the report file.")
-	renderCmd.Flags().StringVarP(&driver, "driver", "d", "cli", "Driver to use for rendering the report. Accepted values are 'cli'and 'html'.")
+	renderCmd.Flags().StringVarP(&driver, "driver", "d", "cli", "Driver to use for rendering the report. Accepted values are 'cli', 'html' and 'html-file'.")
 	renderCmd.Flags().StringVarP(&address, "address", "a", "localhost", "Address to access the html report.")
-	renderCmd.Flags().StringVarP(&address, "port", "p", "8080", "Port to access the html report.")
+	renderCmd.Flags().StringSliceVar(&percentiles, "percentiles", []string{"50", "90", "95", "99"}, "Response time percentiles to render (i.e. 50,99,99.9).")
+	renderCmd.Flags().StringVarP(&htmlOutputPath, "output", "o", "./report.html", "The path to store the html-file report.")
+	renderCmd.Flags().BoolVarP(&forceOverWrite, "force", "F", false, "Force overwrite for the html-file report.")
+	renderCmd.Flags().StringVarP(&port, "port", "p", "8080", "Port to access the html report.")
 }
@@ -0,0 +1,23 @@
+package cmd
+
+import "testing"
+
+func TestParsePercentiles(t *testing.T) {
+	parsedPercentiles, err := parsePercentiles([]string{"50", " 99.9"})
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(parsedPercentiles) != 2 || parsedPercentiles[0] != 50 || parsedPercentiles[1] != 99.9 {
+		t.Errorf("Unexpected percentiles: %v", parsedPercentiles)
+	}
+
+	for _, percentile := range []string{"p99", "0", "101"} {
+		expected := "Invalid percentile: " + percentile
+
+		if _, err := parsePercentiles([]string{percentile}); err == nil || err.Error() != expected {
+			t.Errorf("Expected to get %q but got %v", expected, err)
+		}
+	}
+}
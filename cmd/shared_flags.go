@@ -1,13 +1,24 @@
 package cmd
 
-import "github.com/spf13/cobra"
+import (
+	"time"
+
+	"github.com/spf13/cobra"
+)
 
 var (
-	forceOverWrite bool
-	outputPath     string
+	forceOverWrite                      bool
+	outputPath                          string
+	concurrencyWindow, timeSeriesBucket time.Duration
+	thresholds                          []string
 )
 
+const thresholdUsage = "Threshold which the report should meet in the format of 'metric[{url=path}] operator value' (i.e. 'avg_latency{url=/api} < 200ms', 'success_rate > 99.5%' or 'timeouts == 0'). The command exits with code 3 when a threshold fails. This can be used multiple times."
+
 func initSharedFlags(cmd *cobra.Command) {
 	cmd.Flags().BoolVarP(&forceOverWrite, "force", "F", false, "Force overwrite for the report file.")
 	cmd.Flags().StringVarP(&outputPath, "output", "o", "./report.json", "The path to store the report of benchmark.")
+	cmd.Flags().DurationVar(&timeSeriesBucket, "time-bucket", time.Second, "Size of the time series buckets in the report (0 disables the time series).")
+	cmd.Flags().DurationVar(&concurrencyWindow, "window", time.Second, "Time window to group the concurrency results in the report.")
+	cmd.Flags().StringArrayVar(&thresholds, "threshold", []string{}, thresholdUsage)
 }
@@ -0,0 +1,187 @@
+package cmd
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/sasanrose/gbench/bench"
+	"github.com/sasanrose/gbench/report"
+	"github.com/spf13/cobra"
+)
+
+var workerCmd = &cobra.Command{
+	Use:   "worker",
+	Short: "Runs benchmarks on behalf of a coordinator",
+	Long: `Listens for a coordinator which sends the JSON configuration of a benchmark,
+starts it together with the other workers and gathers its report.
+Paths in the configuration (i.e. feeder, body or certificates) are read on the
+machine of the worker.
+
+Anyone who can reach the worker with its token can make it read local files
+and send traffic to any host, so keep the token secret and only listen on a
+trusted network. The worker listens on the loopback interface by default and
+the token is sent over plain HTTP.
+
+Sample usage:
+gbench worker --listen 10.0.0.2:7777 --token secret`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if workerToken == "" {
+			exitWithError("No token is provided")
+		}
+
+		log.Printf("Waiting for a coordinator on %s...", listenAddress)
+
+		if err := http.ListenAndServe(listenAddress, newWorkerHandler(workerToken)); err != nil {
+			exitWithError(fmt.Sprintf("Could not listen on %s: %v\n", listenAddress, err))
+		}
+	},
+}
+
+// WorkerConfig defines the benchmark which a coordinator sends to its workers.
+type WorkerConfig struct {
+	Config            *JSONConfig   `json:"config"`
+	TimeSeriesBucket  time.Duration `json:"time-bucket"`
+	ConcurrencyWindow time.Duration `json:"window"`
+}
+
+// worker runs the benchmarks of a coordinator. A benchmark is first prepared
+// with its configuration and then started, so the coordinator can start all
+// of its workers together once they are all ready. A prepared or running
+// benchmark can be stopped, in which case its partial report is sent.
+type worker struct {
+	lock     sync.Mutex
+	running  bool
+	b        *bench.Bench
+	result   *report.Result
+	ctx      context.Context
+	stopFunc context.CancelFunc
+}
+
+// newWorkerHandler returns the handler of a worker which only accepts the
+// requests with the given token.
+func newWorkerHandler(token string) http.Handler {
+	w := &worker{}
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("POST /prepare", w.prepare)
+	mux.HandleFunc("POST /start", w.start)
+	mux.HandleFunc("POST /stop", w.stop)
+
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if subtle.ConstantTimeCompare([]byte(req.Header.Get("Authorization")), []byte("Bearer "+token)) != 1 {
+			http.Error(rw, "Invalid token", http.StatusUnauthorized)
+			return
+		}
+
+		mux.ServeHTTP(rw, req)
+	})
+}
+
+// prepare creates the benchmark of a configuration.
+func (w *worker) prepare(rw http.ResponseWriter, req *http.Request) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if w.running {
+		http.Error(rw, "A benchmark is already running", http.StatusConflict)
+		return
+	}
+
+	config := &WorkerConfig{}
+
+	if err := json.NewDecoder(req.Body).Decode(config); err != nil {
+		http.Error(rw, fmt.Sprintf("Invalid configuration: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	if config.Config == nil {
+		http.Error(rw, "No configuration is provided", http.StatusBadRequest)
+		return
+	}
+
+	configurations, err := getJSONConfigurations(config.Config)
+
+	if err != nil {
+		http.Error(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	timeSeriesBucket, concurrencyWindow = config.TimeSeriesBucket, config.ConcurrencyWindow
+
+	w.b, w.result, err = prepareBench(configurations)
+
+	if err != nil {
+		http.Error(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if w.stopFunc != nil {
+		w.stopFunc()
+	}
+
+	w.ctx, w.stopFunc = context.WithCancel(context.Background())
+
+	log.Printf("Prepared a benchmark of %s", config.Config.Host)
+}
+
+// start runs the prepared benchmark and responds with its report. The
+// benchmark stops when it is stopped or when the coordinator goes away.
+func (w *worker) start(rw http.ResponseWriter, req *http.Request) {
+	w.lock.Lock()
+
+	if w.running || w.b == nil {
+		w.lock.Unlock()
+		http.Error(rw, "No benchmark is prepared", http.StatusConflict)
+		return
+	}
+
+	b, result, ctx, stopFunc := w.b, w.result, w.ctx, w.stopFunc
+	w.running, w.b, w.result = true, nil, nil
+	w.lock.Unlock()
+
+	defer context.AfterFunc(req.Context(), stopFunc)()
+
+	defer func() {
+		w.lock.Lock()
+		w.running, w.ctx, w.stopFunc = false, nil, nil
+		stopFunc()
+		w.lock.Unlock()
+	}()
+
+	log.Print("Starting the benchmark...")
+
+	if err := b.Exec(ctx); err != nil && err != context.Canceled {
+		http.Error(rw, fmt.Sprintf("Could not execute the benchmark: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	log.Print("Sending the report to the coordinator...")
+	rw.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(rw).Encode(result)
+}
+
+// stop stops the prepared or running benchmark.
+func (w *worker) stop(rw http.ResponseWriter, req *http.Request) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if w.stopFunc == nil {
+		http.Error(rw, "No benchmark is prepared", http.StatusConflict)
+		return
+	}
+
+	log.Print("Stopping the benchmark...")
+	w.stopFunc()
+}
+
+func init() {
+	rootCmd.AddCommand(workerCmd)
+
+	initWorkerFlags()
+}
@@ -0,0 +1,11 @@
+package cmd
+
+var (
+	listenAddress string
+	workerToken   string
+)
+
+func initWorkerFlags() {
+	workerCmd.Flags().StringVarP(&listenAddress, "listen", "l", "127.0.0.1:7777", "Address to listen for the coordinator on.")
+	workerCmd.Flags().StringVar(&workerToken, "token", "", "Secret token which the coordinator should send.")
+}
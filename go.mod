@@ -0,0 +1,15 @@
+module github.com/sasanrose/gbench
+
+go 1.26
+
+require (
+	github.com/scylladb/termtables v0.0.0-20191203121021-c4c0b6d42ff4
+	github.com/spf13/cobra v0.0.3
+	github.com/ttacon/chalk v0.0.0-20160626202418-22c06c80ed31
+)
+
+require (
+	github.com/inconshreveable/mousetrap v1.1.0 // indirect
+	github.com/mattn/go-runewidth v0.0.3 // indirect
+	github.com/spf13/pflag v1.0.2 // indirect
+)
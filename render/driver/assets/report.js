@@ -0,0 +1,50 @@
+(function () {
+  // Toggles a series of a line chart when its legend is clicked.
+  document.querySelectorAll(".line-chart").forEach(function (chart) {
+    chart.querySelectorAll(".legend button").forEach(function (button) {
+      button.addEventListener("click", function () {
+        var series = chart.querySelector('.series[data-series="' + button.dataset.series + '"]');
+
+        button.classList.toggle("hidden");
+        series.classList.toggle("hidden");
+      });
+    });
+  });
+
+  function cellValue(row, index) {
+    var text = row.cells[index].textContent.trim();
+    var number = parseFloat(text);
+
+    return isNaN(number) ? text : number;
+  }
+
+  // Sorts a table by the clicked column. Clicking again reverses the order.
+  document.querySelectorAll("table.sortable").forEach(function (table) {
+    var headers = table.querySelectorAll("thead th");
+
+    headers.forEach(function (header, index) {
+      header.addEventListener("click", function () {
+        var ascending = !header.classList.contains("asc");
+        var body = table.tBodies[0];
+        var rows = Array.prototype.slice.call(body.rows);
+
+        rows.sort(function (a, b) {
+          var x = cellValue(a, index);
+          var y = cellValue(b, index);
+          var order = x < y ? -1 : x > y ? 1 : 0;
+
+          return ascending ? order : -order;
+        });
+
+        headers.forEach(function (h) {
+          h.classList.remove("asc", "desc");
+        });
+
+        header.classList.add(ascending ? "asc" : "desc");
+        rows.forEach(function (row) {
+          body.appendChild(row);
+        });
+      });
+    });
+  });
+})();
@@ -0,0 +1,159 @@
+package driver
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"math"
+	"time"
+)
+
+const (
+	chartWidth     = 640
+	chartHeight    = 240
+	chartPadding   = 40
+	barHeight      = 22
+	barLabelWidth  = 140
+	barValueMargin = 80
+)
+
+// bar is a single bar of a bar chart.
+type bar struct {
+	label string
+	value float64
+	text  string
+	class string
+}
+
+// series is a named line of a line chart.
+type series struct {
+	name   string
+	class  string
+	points []float64
+}
+
+// getBarChart draws a horizontal bar chart as an inline SVG. Bar values are
+// scaled to the largest value.
+func getBarChart(bars []*bar) template.HTML {
+	if len(bars) == 0 {
+		return ""
+	}
+
+	max := 0.0
+
+	for _, b := range bars {
+		max = math.Max(max, b.value)
+	}
+
+	height := len(bars)*barHeight + 10
+	width := chartWidth - barLabelWidth - barValueMargin
+	buf := &bytes.Buffer{}
+
+	fmt.Fprintf(buf, `<svg class="chart" viewBox="0 0 %d %d" role="img">`, chartWidth, height)
+
+	for i, b := range bars {
+		y := i*barHeight + 5
+		length := 0.0
+
+		if max > 0 {
+			length = b.value / max * float64(width)
+		}
+
+		fmt.Fprintf(buf, `<g class="bar %s"><title>%s: %s</title>`, b.class, template.HTMLEscapeString(b.label), template.HTMLEscapeString(b.text))
+		fmt.Fprintf(buf, `<text x="%d" y="%d" text-anchor="end">%s</text>`, barLabelWidth-8, y+15, template.HTMLEscapeString(b.label))
+		fmt.Fprintf(buf, `<rect x="%d" y="%d" width="%.2f" height="%d"></rect>`, barLabelWidth, y+2, length, barHeight-6)
+		fmt.Fprintf(buf, `<text x="%.2f" y="%d">%s</text></g>`, float64(barLabelWidth)+length+6, y+15, template.HTMLEscapeString(b.text))
+	}
+
+	buf.WriteString(`</svg>`)
+
+	return template.HTML(buf.String())
+}
+
+// getLineChart draws the given series as an inline SVG. Points are labeled
+// using their index and the series can be toggled using the legend.
+func getLineChart(lines []*series, label func(index int) string, unit string) template.HTML {
+	points, max := 0, 0.0
+
+	for _, line := range lines {
+		if len(line.points) > points {
+			points = len(line.points)
+		}
+
+		for _, point := range line.points {
+			max = math.Max(max, point)
+		}
+	}
+
+	if points == 0 {
+		return ""
+	}
+
+	if max == 0 {
+		max = 1
+	}
+
+	width := float64(chartWidth - 2*chartPadding)
+	height := float64(chartHeight - 2*chartPadding)
+	step := width
+
+	if points > 1 {
+		step = width / float64(points-1)
+	}
+
+	buf := &bytes.Buffer{}
+
+	fmt.Fprintf(buf, `<figure class="line-chart"><svg class="chart" viewBox="0 0 %d %d" role="img">`, chartWidth, chartHeight)
+	fmt.Fprintf(buf, `<g class="axis"><line x1="%d" y1="%d" x2="%d" y2="%d"></line>`, chartPadding, chartPadding, chartPadding, chartHeight-chartPadding)
+	fmt.Fprintf(buf, `<line x1="%d" y1="%d" x2="%d" y2="%d"></line>`, chartPadding, chartHeight-chartPadding, chartWidth-chartPadding, chartHeight-chartPadding)
+	fmt.Fprintf(buf, `<text x="%d" y="%d" text-anchor="end">%s</text>`, chartPadding-4, chartPadding+4, template.HTMLEscapeString(formatChartValue(max, unit)))
+	fmt.Fprintf(buf, `<text x="%d" y="%d" text-anchor="end">0</text>`, chartPadding-4, chartHeight-chartPadding+4)
+	fmt.Fprintf(buf, `<text x="%d" y="%d">%s</text>`, chartPadding, chartHeight-chartPadding+16, template.HTMLEscapeString(label(0)))
+	fmt.Fprintf(buf, `<text x="%d" y="%d" text-anchor="end">%s</text></g>`, chartWidth-chartPadding, chartHeight-chartPadding+16, template.HTMLEscapeString(label(points-1)))
+
+	for index, line := range lines {
+		fmt.Fprintf(buf, `<g class="series %s" data-series="%d"><polyline points="`, line.class, index)
+
+		for i, point := range line.points {
+			fmt.Fprintf(buf, "%.2f,%.2f ", float64(chartPadding)+float64(i)*step, float64(chartHeight-chartPadding)-point/max*height)
+		}
+
+		buf.WriteString(`"></polyline>`)
+
+		for i, point := range line.points {
+			fmt.Fprintf(buf, `<circle cx="%.2f" cy="%.2f" r="3"><title>%s at %s: %s</title></circle>`,
+				float64(chartPadding)+float64(i)*step,
+				float64(chartHeight-chartPadding)-point/max*height,
+				template.HTMLEscapeString(line.name),
+				template.HTMLEscapeString(label(i)),
+				template.HTMLEscapeString(formatChartValue(point, unit)))
+		}
+
+		buf.WriteString(`</g>`)
+	}
+
+	buf.WriteString(`</svg><figcaption class="legend">`)
+
+	for index, line := range lines {
+		fmt.Fprintf(buf, `<button type="button" class="%s" data-series="%d">%s</button>`, line.class, index, template.HTMLEscapeString(line.name))
+	}
+
+	buf.WriteString(`</figcaption></figure>`)
+
+	return template.HTML(buf.String())
+}
+
+// getIntervalLabel labels chart points which are an interval apart.
+func getIntervalLabel(interval time.Duration) func(int) string {
+	return func(index int) string {
+		return fmt.Sprint(time.Duration(index) * interval)
+	}
+}
+
+func formatChartValue(value float64, unit string) string {
+	if unit == "ms" {
+		return time.Duration(value * float64(time.Millisecond)).Round(time.Microsecond).String()
+	}
+
+	return fmt.Sprintf("%.0f%s", value, unit)
+}
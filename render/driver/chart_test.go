@@ -0,0 +1,41 @@
+package driver
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBarChart(t *testing.T) {
+	if chart := getBarChart([]*bar{}); chart != "" {
+		t.Errorf("Expected no chart but got %s", chart)
+	}
+
+	chart := string(getBarChart([]*bar{
+		{"200", 10, "10", "success"},
+		{"<500>", 5, "5", "failure"},
+	}))
+
+	for _, str := range []string{"<svg", `class="bar success"`, `width="420.00"`, `width="210.00"`, "&lt;500&gt;"} {
+		if !strings.Contains(chart, str) {
+			t.Errorf("Could not find %s in the chart: %s", str, chart)
+		}
+	}
+}
+
+func TestLineChart(t *testing.T) {
+	if chart := getLineChart([]*series{}, getIntervalLabel(time.Second), ""); chart != "" {
+		t.Errorf("Expected no chart but got %s", chart)
+	}
+
+	chart := string(getLineChart([]*series{
+		{name: "p50", points: []float64{1, 2, 4}},
+		{name: "p99", class: "series-2", points: []float64{2, 4, 8}},
+	}, getIntervalLabel(time.Second), "ms"))
+
+	for _, str := range []string{"<polyline", `data-series="1"`, "p99 at 2s: 8ms", ">8ms<", ">2s<", "<button"} {
+		if !strings.Contains(chart, str) {
+			t.Errorf("Could not find %s in the chart: %s", str, chart)
+		}
+	}
+}
@@ -11,21 +11,43 @@ import (
 	"github.com/sasanrose/gbench/report"
 )
 
+var defaultPercentiles = []float64{50, 90, 95, 99}
+
 type cli struct {
-	output io.Writer
+	output      io.Writer
+	percentiles []float64
+}
+
+// NewCli creates a new cli renderer for benchmark report. A config can be
+// created on the fly or using the predefined functions.
+func NewCli(configurations ...func(*cli)) render.Renderer {
+	r := &cli{output: os.Stdout, percentiles: defaultPercentiles}
+
+	for _, config := range configurations {
+		config(r)
+	}
+
+	return r
 }
 
-// NewCli creates a new cli renderer for benchmark report.
-func NewCli() render.Renderer {
-	return &cli{os.Stdout}
+// WithPercentiles creates a config to set the response time percentiles to
+// render (i.e. 50, 99 or 99.9).
+func WithPercentiles(percentiles []float64) func(*cli) {
+	return func(r *cli) {
+		r.percentiles = percentiles
+	}
 }
 
 // Render will output the result of the report to cli.
 func (r *cli) Render(result *report.Result) error {
-	tableGen := &tableGenerator{result}
+	tableGen := &tableGenerator{r: result, percentiles: r.percentiles}
 	table := tableGen.getBenchResultTable()
 	urlTables := tableGen.getURLTables()
-	concurrencyTables := tableGen.getConcurrencyTables()
+	errorTable := tableGen.getErrorTable()
+	stageTable := tableGen.getStageTable()
+	scenarioTables := tableGen.getScenarioTables()
+	timeSeriesTables := tableGen.getTimeSeriesTables()
+	concurrencyTable := tableGen.getConcurrencyTable()
 
 	fmt.Fprint(r.output, table.Render())
 
@@ -33,8 +55,24 @@ func (r *cli) Render(result *report.Result) error {
 		fmt.Fprint(r.output, urlTable.Render())
 	}
 
-	for i := 0; i < len(concurrencyTables); i++ {
-		fmt.Fprint(r.output, concurrencyTables[i].Render())
+	if errorTable != nil {
+		fmt.Fprint(r.output, errorTable.Render())
+	}
+
+	if stageTable != nil {
+		fmt.Fprint(r.output, stageTable.Render())
+	}
+
+	for _, scenarioTable := range scenarioTables {
+		fmt.Fprint(r.output, scenarioTable.Render())
+	}
+
+	for _, timeSeriesTable := range timeSeriesTables {
+		fmt.Fprint(r.output, timeSeriesTable.Render())
+	}
+
+	if concurrencyTable != nil {
+		fmt.Fprint(r.output, concurrencyTable.Render())
 	}
 
 	return nil
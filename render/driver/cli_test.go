@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/sasanrose/gbench/render"
 	"github.com/sasanrose/gbench/report"
@@ -27,17 +28,32 @@ var expectedStringsInOutput = []string{
 	"Shortest response time",
 	"Longest response time",
 	"Average response time",
+	"p50 response time",
+	"p99.9 response time",
 	"Final result for http://testurl1.com",
 	"Total data received",
 	"Response with status code 200",
 	"Response with status code 201",
 	"Response with status code 500",
+	"Responses over HTTP/2.0",
+	"Followed redirects",
+	"Redirected to http://testurl1.com/final",
+	"New connections",
+	"Reused connections",
+	"Failed assertion json:status=ok",
 	"Failed requests",
 	"Timedout requests",
 	"Sum response times",
 	"Shortest response time",
 	"Longest response time",
 	"Average response time",
+	"p50 response time",
+	"p99.9 response time",
+	"Average DNS lookup time",
+	"Average connect time",
+	"Average TLS handshake time",
+	"Average time to first byte",
+	"Average download time",
 	"Final result for http://testurl2.com",
 	"Total data received",
 	"Response with status code 200",
@@ -59,17 +75,11 @@ var expectedStringsInOutput = []string{
 	"Shortest response time",
 	"Longest response time",
 	"Average response time",
-	"Result for concurrent requests batch 1",
-	"URL",
-	"http://testurl1.com",
-	"http://testurl2.com",
-	"http://testurl3.com",
-	"Result for concurrent requests batch 2",
-	"URL",
-	"http://testurl1.com",
-	"http://testurl2.com",
-	"http://testurl3.com",
-	"Result for concurrent requests batch 3",
+	"Result for load stages",
+	"ramp",
+	"hold",
+	"Result for concurrent requests batches",
+	"Batch",
 	"URL",
 	"http://testurl1.com",
 	"http://testurl2.com",
@@ -84,11 +94,26 @@ func TestNew(t *testing.T) {
 	}
 }
 
+func TestPercentiles(t *testing.T) {
+	r := NewCli().(*cli)
+
+	if len(r.percentiles) != len(defaultPercentiles) {
+		t.Errorf("Expected default percentiles but got %v", r.percentiles)
+	}
+
+	r = NewCli(WithPercentiles([]float64{99.99})).(*cli)
+
+	if len(r.percentiles) != 1 || r.percentiles[0] != 99.99 {
+		t.Errorf("Unexpected percentiles: %v", r.percentiles)
+	}
+}
+
 func TestOutput(t *testing.T) {
 	buf := bytes.NewBuffer([]byte{})
 
 	r := &cli{}
 	r.output = buf
+	r.percentiles = []float64{50, 99.9}
 
 	result := &report.Result{}
 	result.Init(2)
@@ -108,3 +133,146 @@ func TestOutput(t *testing.T) {
 		}
 	}
 }
+
+func TestConcurrencyWindowTable(t *testing.T) {
+	result := &report.Result{}
+	result.Init(2)
+	result.ConcurrencyWindow = time.Second
+
+	g := &tableGenerator{r: result}
+
+	if g.getConcurrencyTable() != nil {
+		t.Error("Did not expect a concurrency table without results")
+	}
+
+	result.ConcurrencyResult["http://testurl2.com"] = []*report.ConcurrencyResult{{TotalRequests: 1}, {TotalRequests: 2}, {TotalRequests: 3}}
+	result.ConcurrencyResult["http://testurl1.com"] = []*report.ConcurrencyResult{{TotalRequests: 4}}
+	result.URLs["http://testurl1.com"], result.URLs["http://testurl2.com"] = true, true
+
+	if title := g.getConcurrencyTitle(); title != "Result for time windows of 1s" {
+		t.Errorf("Unexpected title: %s", title)
+	}
+
+	rows := g.getConcurrencyRows()
+	labels := []string{}
+
+	for _, row := range rows {
+		labels = append(labels, row.label+" "+row.url)
+	}
+
+	expected := []string{"0s - 1s http://testurl1.com", "0s - 1s http://testurl2.com", "1s - 2s http://testurl2.com", "2s - 3s http://testurl2.com"}
+
+	if strings.Join(labels, ",") != strings.Join(expected, ",") || rows[3].result.TotalRequests != 3 {
+		t.Errorf("Unexpected rows: %v", labels)
+	}
+
+	output := g.getConcurrencyTable().Render()
+
+	if strings.Count(output, "Result for time windows") != 1 || !strings.Contains(output, "Time window") || !strings.Contains(output, "2s - 3s") {
+		t.Errorf("Expected a single table with a row for each window but got %s", output)
+	}
+}
+
+func TestCorrectedPercentiles(t *testing.T) {
+	buf := bytes.NewBuffer([]byte{})
+
+	result := &report.Result{}
+	result.Init(2)
+	result.SetExpectedInterval(100 * time.Microsecond)
+
+	addTestData(result)
+
+	r := &cli{output: buf, percentiles: []float64{99}}
+	r.Render(result)
+
+	for _, str := range []string{"p99 response time (raw)", "p99 response time (corrected)"} {
+		if !strings.Contains(buf.String(), str) {
+			t.Errorf("Could not find %s in the output", str)
+		}
+	}
+}
+
+func TestTimeSeriesTables(t *testing.T) {
+	result := &report.Result{}
+	result.Init(2)
+	result.SetStartTime(time.Now())
+	result.SetTimeSeriesInterval(time.Second)
+
+	addTestData(result)
+
+	g := &tableGenerator{r: result, percentiles: []float64{99}}
+	tables := g.getTimeSeriesTables()
+
+	if len(tables) != 3 {
+		t.Fatalf("Expected a time series table per URL but got %d", len(tables))
+	}
+
+	output := tables[0].Render()
+
+	for _, str := range []string{"Time series for http://testurl1.com", "Sent", "2xx", "5xx", "p99"} {
+		if !strings.Contains(output, str) {
+			t.Errorf("Could not find %s in the output", str)
+		}
+	}
+}
+
+func TestScenarioTables(t *testing.T) {
+	result := &report.Result{}
+	result.Init(2)
+
+	addTestData(result)
+
+	result.AddScenario("checkout", []string{"http://testurl1.com", "http://testurl3.com"})
+	result.AddScenarioResult("checkout", 2*time.Millisecond, "")
+	result.AddScenarioResult("checkout", time.Millisecond, "http://testurl3.com")
+
+	g := &tableGenerator{r: result, percentiles: []float64{99}}
+	tables := g.getScenarioTables()
+
+	if len(tables) != 2 {
+		t.Fatalf("Expected a result and a step table per scenario but got %d", len(tables))
+	}
+
+	output := tables[0].Render() + tables[1].Render()
+
+	for _, str := range []string{"Result for scenario checkout", "Iterations", "%50.00", "p99 iteration time", "Steps of scenario checkout", "Failed iterations"} {
+		if !strings.Contains(output, str) {
+			t.Errorf("Could not find %s in the output", str)
+		}
+	}
+
+	values := g.getScenarioStepValues("checkout", "http://testurl3.com")
+
+	if values[1] != 5 || values[2] != 0 || values[3] != 3 || values[4] != 2 || values[6] != 1 {
+		t.Errorf("Unexpected step values: %v", values)
+	}
+}
+
+func TestErrorTable(t *testing.T) {
+	result := &report.Result{}
+	result.Init(2)
+
+	g := &tableGenerator{r: result}
+
+	if g.getErrorTable() != nil {
+		t.Error("Did not expect an error table without errors")
+	}
+
+	addTestData(result)
+
+	result.AddError("http://testurl2.com", report.ErrorConnectionRefused, "connect: connection refused")
+	result.AddError("http://testurl2.com", report.ErrorConnectionRefused, "connect: connection refused")
+	result.AddError("http://testurl1.com", report.ErrorEOF, "EOF")
+
+	output := g.getErrorTable().Render()
+
+	for _, str := range []string{"Errors", "http://testurl1.com", "eof", "http://testurl2.com", "connection-refused", "connect: connection refused"} {
+		if !strings.Contains(output, str) {
+			t.Errorf("Could not find %s in the output", str)
+		}
+	}
+
+	if strings.Index(output, "http://testurl1.com") > strings.Index(output, "http://testurl2.com") {
+		t.Error("Expected the errors to be sorted by URL")
+	}
+}
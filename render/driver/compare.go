@@ -0,0 +1,261 @@
+package driver
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/sasanrose/gbench/render"
+	"github.com/sasanrose/gbench/report"
+	"github.com/scylladb/termtables"
+	"github.com/ttacon/chalk"
+)
+
+const defaultRegressionThreshold = 5
+
+type cliComparer struct {
+	output      io.Writer
+	percentiles []float64
+	threshold   float64
+}
+
+// NewCliComparer creates a new cli comparer for benchmark reports. A config
+// can be created on the fly or using the predefined functions.
+func NewCliComparer(configurations ...func(*cliComparer)) render.Comparer {
+	c := &cliComparer{output: os.Stdout, percentiles: defaultPercentiles, threshold: defaultRegressionThreshold}
+
+	for _, config := range configurations {
+		config(c)
+	}
+
+	return c
+}
+
+// WithComparePercentiles creates a config to set the response time
+// percentiles to compare (i.e. 50, 99 or 99.9).
+func WithComparePercentiles(percentiles []float64) func(*cliComparer) {
+	return func(c *cliComparer) {
+		c.percentiles = percentiles
+	}
+}
+
+// WithRegressionThreshold creates a config to set the percent change from
+// which a worse value is highlighted as a regression (and a better value as
+// an improvement).
+func WithRegressionThreshold(threshold float64) func(*cliComparer) {
+	return func(c *cliComparer) {
+		c.threshold = threshold
+	}
+}
+
+// Compare outputs a table comparing the whole benchmarks and a table for each
+// of the URLs. Every report is compared to the first one.
+func (c *cliComparer) Compare(names []string, results []*report.Result) error {
+	if len(results) < 2 || len(names) != len(results) {
+		return errors.New("At least two named reports are needed to compare")
+	}
+
+	summaries := make([]*report.Summary, 0, len(results))
+
+	for _, result := range results {
+		summaries = append(summaries, result.Summary())
+	}
+
+	fmt.Fprint(c.output, c.getComparisonTable("Comparison of the final benchmark result", names, summaries).Render())
+
+	for _, url := range getComparedURLs(results) {
+		urlSummaries := make([]*report.Summary, 0, len(results))
+
+		for _, result := range results {
+			urlSummaries = append(urlSummaries, result.URLSummary(url))
+		}
+
+		fmt.Fprint(c.output, c.getComparisonTable(fmt.Sprintf("Comparison for %s", url), names, urlSummaries).Render())
+	}
+
+	return nil
+}
+
+// getComparedURLs returns the URLs of all the reports in a stable order.
+func getComparedURLs(results []*report.Result) []string {
+	urls := make(map[string]bool)
+
+	for _, result := range results {
+		for url := range result.URLs {
+			urls[url] = true
+		}
+	}
+
+	sortedURLs := make([]string, 0, len(urls))
+
+	for url := range urls {
+		sortedURLs = append(sortedURLs, url)
+	}
+
+	sort.Strings(sortedURLs)
+
+	return sortedURLs
+}
+
+// metric is a compared value. An increase of a metric with a positive
+// direction is an improvement while it is a regression with a negative one.
+// Metrics without a direction are never highlighted.
+type metric struct {
+	title     string
+	direction int
+	format    func(float64) string
+	value     func(*report.Summary) float64
+}
+
+func (c *cliComparer) getMetrics() []*metric {
+	formatDuration := func(v float64) string { return time.Duration(v).String() }
+
+	metrics := []*metric{
+		{"Total requests", 0, func(v float64) string { return fmt.Sprintf("%.0f", v) }, func(s *report.Summary) float64 {
+			return float64(s.Requests)
+		}},
+		{"Throughput", 1, func(v float64) string { return fmt.Sprintf("%.2f/s", v) }, func(s *report.Summary) float64 {
+			if s.TotalTime <= 0 {
+				return math.NaN()
+			}
+
+			return float64(s.Requests) / s.TotalTime.Seconds()
+		}},
+		{"Success rate", 1, func(v float64) string { return fmt.Sprintf("%%%.2f", v) }, func(s *report.Summary) float64 {
+			if s.Requests == 0 {
+				return math.NaN()
+			}
+
+			return float64(s.Successful*100) / float64(s.Requests)
+		}},
+		{"Average response time", -1, formatDuration, func(s *report.Summary) float64 {
+			if s.ResponseTimes == 0 {
+				return math.NaN()
+			}
+
+			return float64(s.ResponseTime.Nanoseconds() / int64(s.ResponseTimes))
+		}},
+		{"Shortest response time", -1, formatDuration, func(s *report.Summary) float64 {
+			return float64(s.ShortestTime)
+		}},
+		{"Longest response time", -1, formatDuration, func(s *report.Summary) float64 {
+			return float64(s.LongestTime)
+		}},
+	}
+
+	for _, p := range c.percentiles {
+		p := p
+
+		metrics = append(metrics, &metric{fmt.Sprintf("%s response time", percentileName(p)), -1, formatDuration, func(s *report.Summary) float64 {
+			if s.Histogram == nil || s.Histogram.TotalCount == 0 {
+				return math.NaN()
+			}
+
+			return float64(s.Histogram.Percentile(p))
+		}})
+	}
+
+	return append(metrics, &metric{"Total data received", 0, func(v float64) string { return fmt.Sprintf("%.5f MB", v/math.Pow(2, 20)) }, func(s *report.Summary) float64 {
+		return float64(s.ReceivedDataLength)
+	}})
+}
+
+func (c *cliComparer) getComparisonTable(title string, names []string, summaries []*report.Summary) *termtables.Table {
+	g := &tableGenerator{}
+
+	table := termtables.CreateTable()
+	table.AddTitle(g.getColoredString(title, chalk.Blue))
+
+	headers := []interface{}{g.getColoredString("Metric", chalk.Cyan), g.getColoredString(names[0], chalk.Cyan)}
+
+	for _, name := range names[1:] {
+		headers = append(headers, g.getColoredString(name, chalk.Cyan), g.getColoredString("Change", chalk.Cyan))
+	}
+
+	table.AddHeaders(headers...)
+
+	for _, m := range c.getMetrics() {
+		values := make([]float64, 0, len(summaries))
+
+		for _, s := range summaries {
+			if s == nil {
+				values = append(values, math.NaN())
+				continue
+			}
+
+			values = append(values, m.value(s))
+		}
+
+		// Metrics which are missing in all the reports (i.e. percentiles of
+		// old reports) are not compared.
+		if allNaN(values) {
+			continue
+		}
+
+		cells := []interface{}{g.getColoredString(m.title, chalk.Cyan), g.getColoredString(formatValue(m, values[0]), chalk.Cyan)}
+
+		for _, value := range values[1:] {
+			change, color := c.getChange(m, values[0], value)
+			cells = append(cells, g.getColoredString(formatValue(m, value), chalk.Cyan), g.getColoredString(change, color))
+		}
+
+		table.AddRow(cells...)
+	}
+
+	return table
+}
+
+// getChange returns the delta and the percent change of a value compared to
+// the baseline along with the color which highlights it.
+func (c *cliComparer) getChange(m *metric, baseline, value float64) (string, chalk.Color) {
+	if math.IsNaN(baseline) || math.IsNaN(value) {
+		return "-", chalk.Cyan
+	}
+
+	delta := value - baseline
+	sign := "+"
+
+	if delta < 0 {
+		sign = "-"
+	}
+
+	change := sign + m.format(math.Abs(delta))
+
+	if baseline == 0 {
+		return change, chalk.Cyan
+	}
+
+	percent := delta * 100 / math.Abs(baseline)
+	change = fmt.Sprintf("%s (%+.2f%%)", change, percent)
+
+	switch {
+	case m.direction == 0 || math.Abs(percent) < c.threshold:
+		return change, chalk.Cyan
+	case percent*float64(m.direction) < 0:
+		return change, chalk.Red
+	}
+
+	return change, chalk.Green
+}
+
+func formatValue(m *metric, value float64) string {
+	if math.IsNaN(value) {
+		return "-"
+	}
+
+	return m.format(value)
+}
+
+func allNaN(values []float64) bool {
+	for _, value := range values {
+		if !math.IsNaN(value) {
+			return false
+		}
+	}
+
+	return true
+}
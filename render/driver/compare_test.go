@@ -0,0 +1,96 @@
+package driver
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sasanrose/gbench/report"
+	"github.com/ttacon/chalk"
+)
+
+func getCompareTestResult(responseTime time.Duration, urls ...string) *report.Result {
+	r := &report.Result{}
+	r.Init(1)
+
+	for _, url := range urls {
+		for i := 0; i < 10; i++ {
+			r.AddSentRequest(url)
+			r.AddResponseTime(url, responseTime)
+			r.AddReceivedDataLength(url, 1024)
+			r.AddResponseStatusCode(url, 200, false)
+		}
+	}
+
+	r.SetTotalDuration(time.Second)
+
+	return r
+}
+
+func TestCompare(t *testing.T) {
+	before := getCompareTestResult(100*time.Millisecond, "http://testurl1.com", "http://testurl2.com")
+	after := getCompareTestResult(200*time.Millisecond, "http://testurl1.com", "http://testurl3.com")
+
+	output := &bytes.Buffer{}
+	c := NewCliComparer(WithComparePercentiles([]float64{99}), WithRegressionThreshold(10))
+	c.(*cliComparer).output = output
+
+	if err := c.Compare([]string{"old.json", "new.json"}, []*report.Result{before, after}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expectedStrings := []string{
+		"Comparison of the final benchmark result",
+		"Comparison for http://testurl1.com",
+		"Comparison for http://testurl2.com",
+		"Comparison for http://testurl3.com",
+		"old.json",
+		"new.json",
+		"Throughput",
+		"20.00/s",
+		"Success rate",
+		"p99 response time",
+		"Total data received",
+		chalk.Red.String() + "+100ms (+100.00%)",
+		"+0.00/s (+0.00%)",
+	}
+
+	for _, str := range expectedStrings {
+		if !strings.Contains(output.String(), str) {
+			t.Errorf("Could not find %s in the output", str)
+		}
+	}
+
+	if err := c.Compare([]string{"old.json"}, []*report.Result{before}); err == nil {
+		t.Error("Expected an error for a single report")
+	}
+}
+
+func TestGetChange(t *testing.T) {
+	c := &cliComparer{threshold: 5}
+	latency := &metric{direction: -1, format: func(v float64) string { return time.Duration(v).String() }}
+	throughput := &metric{direction: 1, format: func(v float64) string { return "x" }}
+	requests := &metric{format: func(v float64) string { return "x" }}
+
+	tests := []struct {
+		m               *metric
+		baseline, value float64
+		change          string
+		color           chalk.Color
+	}{
+		{latency, 100, 150, "+50ns (+50.00%)", chalk.Red},
+		{latency, 100, 50, "-50ns (-50.00%)", chalk.Green},
+		{latency, 100, 102, "+2ns (+2.00%)", chalk.Cyan},
+		{throughput, 100, 50, "-x (-50.00%)", chalk.Red},
+		{throughput, 100, 150, "+x (+50.00%)", chalk.Green},
+		{requests, 100, 150, "+x (+50.00%)", chalk.Cyan},
+		{latency, 0, 50, "+50ns", chalk.Cyan},
+	}
+
+	for _, test := range tests {
+		if change, color := c.getChange(test.m, test.baseline, test.value); change != test.change || color != test.color {
+			t.Errorf("Expected %s for %v -> %v but got %s", test.change, test.baseline, test.value, change)
+		}
+	}
+}
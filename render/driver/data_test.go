@@ -52,9 +52,23 @@ func addTestData(r *report.Result) {
 
 			r.AddReceivedDataLength(url, response.contentLength)
 			r.AddResponseTime(url, response.responseTime)
+			r.AddPhaseTimes(url, report.PhaseTimes{
+				Connect:         response.responseTime / 4,
+				TimeToFirstByte: response.responseTime / 2,
+				Download:        response.responseTime / 4,
+			})
 			r.AddResponseStatusCode(url, response.statusCode, response.failed)
 		}
 	}
 
+	r.AddFailedAssertion("http://testurl1.com", "json:status=ok")
+	r.AddResponseProtocol("http://testurl1.com", "HTTP/2.0")
+	r.AddRedirects("http://testurl1.com", 2, "http://testurl1.com/final")
+	r.AddConnection("http://testurl1.com", false)
+	r.AddConnection("http://testurl1.com", true)
+
+	r.AddStageResponse("ramp", 500*time.Microsecond, false, false)
+	r.AddStageResponse("hold", 0, false, true)
+
 	r.SetTotalDuration(2 * time.Millisecond)
 }
@@ -3,109 +3,582 @@ package driver
 import (
 	"fmt"
 	"math"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
-	"github.com/apcera/termtables"
 	"github.com/sasanrose/gbench/report"
+	"github.com/scylladb/termtables"
 	"github.com/ttacon/chalk"
 )
 
 type tableGenerator struct {
-	r *report.Result
+	r           *report.Result
+	percentiles []float64
+}
+
+// row is a titled value of the report. Rows are shared by the renderers so
+// that they all show the same figures.
+type row struct {
+	title string
+	value interface{}
+	color chalk.Color
 }
 
 func (g *tableGenerator) getBenchResultTable() *termtables.Table {
 	table := termtables.CreateTable()
+	table.AddTitle(g.getColoredString("Final benchmark result", chalk.Blue))
+	g.addRows(table, g.getBenchResultRows())
 
+	return table
+}
+
+func (g *tableGenerator) getBenchResultRows() []*row {
 	successRate := (float64(g.r.SuccessfulRequests*100) / float64(g.r.TotalRequests))
 	failureRate := (float64(g.r.FailedRequests*100) / float64(g.r.TotalRequests))
 	timedoutRate := (float64(g.r.TimedOutRequests*100) / float64(g.r.TotalRequests))
-	averageResponseTime := time.Duration(g.r.TotalResponseTime.Nanoseconds() / int64(g.r.ResponseTimesTotalCount))
+	averageResponseTime := time.Duration(0)
 	transferredData := float64(g.r.TotalReceivedDataLength) / math.Pow(2, 20)
 
-	table.AddTitle(g.getColoredString("Final benchmark result", chalk.Blue))
-	g.addColoredRow(table, chalk.Cyan, "Start time", g.r.StartTime.Format(time.RFC1123))
-	g.addColoredRow(table, chalk.Cyan, "End time", g.r.EndTime.Format(time.RFC1123))
-	g.addColoredRow(table, chalk.Cyan, "Total requests sent", g.r.TotalRequests)
-	g.addColoredRow(table, chalk.Cyan, "Total data received", fmt.Sprintf("%.5f MB", transferredData))
-	g.addColoredRow(table, chalk.Green, "Total successful requests", g.r.SuccessfulRequests)
-	g.addColoredRow(table, chalk.Red, "Total failed requests", g.r.FailedRequests)
-	g.addColoredRow(table, chalk.Yellow, "Total timedout requests", g.r.TimedOutRequests)
-	g.addColoredRow(table, chalk.Green, "Success rate", fmt.Sprintf("%%%.2f", successRate))
-	g.addColoredRow(table, chalk.Red, "Failure rate", fmt.Sprintf("%%%.2f", failureRate))
-	g.addColoredRow(table, chalk.Yellow, "Timedout rate", fmt.Sprintf("%%%.2f", timedoutRate))
-	g.addColoredRow(table, chalk.Cyan, "Total benchmark time", g.r.TotalTime)
-	g.addColoredRow(table, chalk.Cyan, "Sum of all response times", g.r.TotalResponseTime)
-	g.addColoredRow(table, chalk.Cyan, "Shortest response time", g.r.ShortestResponseTime)
-	g.addColoredRow(table, chalk.Cyan, "Longest response time", g.r.LongestResponseTime)
-	g.addColoredRow(table, chalk.Cyan, "Average response time", averageResponseTime)
+	if g.r.ResponseTimesTotalCount > 0 {
+		averageResponseTime = time.Duration(g.r.TotalResponseTime.Nanoseconds() / int64(g.r.ResponseTimesTotalCount))
+	}
 
-	return table
+	rows := []*row{
+		{"Start time", g.r.StartTime.Format(time.RFC1123), chalk.Cyan},
+		{"End time", g.r.EndTime.Format(time.RFC1123), chalk.Cyan},
+	}
+
+	if g.r.StopReason != "" {
+		rows = append(rows, &row{"Stop reason", g.r.StopReason, chalk.Cyan})
+	}
+
+	rows = append(rows,
+		&row{"Total requests sent", g.r.TotalRequests, chalk.Cyan},
+		&row{"Total data received", fmt.Sprintf("%.5f MB", transferredData), chalk.Cyan},
+		&row{"Total successful requests", g.r.SuccessfulRequests, chalk.Green},
+		&row{"Total failed requests", g.r.FailedRequests, chalk.Red},
+		&row{"Total timedout requests", g.r.TimedOutRequests, chalk.Yellow})
+
+	if g.r.DroppedRequests > 0 {
+		rows = append(rows, &row{"Total dropped requests", g.r.DroppedRequests, chalk.Magenta})
+	}
+
+	rows = append(rows,
+		&row{"Success rate", fmt.Sprintf("%%%.2f", successRate), chalk.Green},
+		&row{"Failure rate", fmt.Sprintf("%%%.2f", failureRate), chalk.Red},
+		&row{"Timedout rate", fmt.Sprintf("%%%.2f", timedoutRate), chalk.Yellow},
+		&row{"Total benchmark time", g.r.TotalTime, chalk.Cyan},
+		&row{"Sum of all response times", g.r.TotalResponseTime, chalk.Cyan},
+		&row{"Shortest response time", g.r.ShortestResponseTime, chalk.Cyan},
+		&row{"Longest response time", g.r.LongestResponseTime, chalk.Cyan},
+		&row{"Average response time", averageResponseTime, chalk.Cyan})
+
+	return append(rows, g.getPercentileRows(g.r.Histogram, g.r.CorrectedHistogram)...)
 }
 
 func (g *tableGenerator) getURLTables() []*termtables.Table {
 	urlTables := make([]*termtables.Table, 0)
 
-	for url := range g.r.URLs {
+	for _, url := range g.getURLs() {
 		urlTable := termtables.CreateTable()
 		urlTable.AddTitle(g.getColoredString(fmt.Sprintf("Final result for %s", url), chalk.Blue))
+		g.addRows(urlTable, g.getURLRows(url))
+
+		urlTables = append(urlTables, urlTable)
+	}
+
+	return urlTables
+}
+
+// getURLs returns the benchmarked URLs in a stable order.
+func (g *tableGenerator) getURLs() []string {
+	urls := make([]string, 0, len(g.r.URLs))
+
+	for url := range g.r.URLs {
+		urls = append(urls, url)
+	}
+
+	sort.Strings(urls)
+
+	return urls
+}
+
+func (g *tableGenerator) getURLRows(url string) []*row {
+	rows := []*row{}
+
+	if length, ok := g.r.ReceivedDataLength[url]; ok {
+		transferredData := float64(length) / math.Pow(2, 20)
+		rows = append(rows, &row{"Total data received", fmt.Sprintf("%.5f MB", transferredData), chalk.Cyan})
+	}
+
+	for _, statusCode := range sortedStatusCodes(g.r.ResponseStatusCode[url]) {
+		rows = append(rows, &row{fmt.Sprintf("Response with status code %d", statusCode), g.r.ResponseStatusCode[url][statusCode], chalk.Green})
+	}
+
+	for _, statusCode := range sortedStatusCodes(g.r.FailedResponseStatusCode[url]) {
+		rows = append(rows, &row{fmt.Sprintf("Response with status code %d", statusCode), g.r.FailedResponseStatusCode[url][statusCode], chalk.Red})
+	}
+
+	for _, protocol := range sortedKeys(g.r.Protocols[url]) {
+		rows = append(rows, &row{fmt.Sprintf("Responses over %s", protocol), g.r.Protocols[url][protocol], chalk.Blue})
+	}
+
+	if redirects, ok := g.r.Redirects[url]; ok {
+		rows = append(rows, &row{"Followed redirects", redirects, chalk.Blue})
+	}
 
-		if length, ok := g.r.ReceivedDataLength[url]; ok {
-			transferredData := float64(length) / math.Pow(2, 20)
-			g.addColoredRow(urlTable, chalk.Cyan, "Total data received", fmt.Sprintf("%.5f MB", transferredData))
+	for _, finalURL := range sortedKeys(g.r.FinalURLs[url]) {
+		if finalURL != report.OtherFinalURLs {
+			rows = append(rows, &row{fmt.Sprintf("Redirected to %s", finalURL), g.r.FinalURLs[url][finalURL], chalk.Blue})
 		}
+	}
 
-		if _, ok := g.r.ResponseStatusCode[url]; ok {
-			for statusCode, count := range g.r.ResponseStatusCode[url] {
-				g.addColoredRow(urlTable, chalk.Green, fmt.Sprintf("Response with status code %d", statusCode), count)
-			}
+	if count, ok := g.r.FinalURLs[url][report.OtherFinalURLs]; ok {
+		rows = append(rows, &row{"Redirected to other URLs", count, chalk.Blue})
+	}
+
+	_, hasNew := g.r.NewConnections[url]
+	_, hasReused := g.r.ReusedConnections[url]
+
+	if hasNew || hasReused {
+		rows = append(rows,
+			&row{"New connections", g.r.NewConnections[url], chalk.Blue},
+			&row{"Reused connections", g.r.ReusedConnections[url], chalk.Blue})
+	}
+
+	for _, assertion := range sortedKeys(g.r.FailedAssertions[url]) {
+		rows = append(rows, &row{fmt.Sprintf("Failed assertion %s", assertion), g.r.FailedAssertions[url][assertion], chalk.Red})
+	}
+
+	averageResponseTime := time.Duration(0)
+
+	if g.r.ResponseTimesCount[url] > 0 {
+		averageResponseTime = time.Duration(g.r.ResponseTime[url].Nanoseconds() / int64(g.r.ResponseTimesCount[url]))
+	}
+
+	rows = append(rows,
+		&row{"Failed requests", g.r.FailedResponse[url], chalk.Red},
+		&row{"Timedout requests", g.r.TimedoutResponse[url], chalk.Yellow})
+
+	if dropped, ok := g.r.DroppedRequest[url]; ok {
+		rows = append(rows, &row{"Dropped requests", dropped, chalk.Magenta})
+	}
+
+	rows = append(rows,
+		&row{"Sum response times", g.r.ResponseTime[url], chalk.Cyan},
+		&row{"Shortest response time", g.r.ShortestResponseTimes[url], chalk.Cyan},
+		&row{"Longest response time", g.r.LongestResponseTimes[url], chalk.Cyan},
+		&row{"Average response time", averageResponseTime, chalk.Cyan})
+	rows = append(rows, g.getPercentileRows(g.r.Histograms[url], g.r.CorrectedHistograms[url])...)
+
+	return append(rows, g.getPhaseTimesRows(url)...)
+}
+
+func sortedStatusCodes(statusCodes map[int]int) []int {
+	codes := make([]int, 0, len(statusCodes))
+
+	for statusCode := range statusCodes {
+		codes = append(codes, statusCode)
+	}
+
+	sort.Ints(codes)
+
+	return codes
+}
+
+func sortedKeys(counts map[string]int) []string {
+	keys := make([]string, 0, len(counts))
+
+	for key := range counts {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+// getPercentileRows returns the response time percentiles. When response times
+// corrected for coordinated omission exist, both raw and corrected percentiles
+// are returned with a label.
+func (g *tableGenerator) getPercentileRows(raw, corrected *report.Histogram) []*row {
+	rows := []*row{}
+
+	if raw == nil || raw.TotalCount == 0 {
+		return rows
+	}
+
+	hasCorrected := corrected != nil && corrected.TotalCount > 0
+
+	for _, p := range g.percentiles {
+		title := fmt.Sprintf("%s response time", percentileName(p))
+
+		if !hasCorrected {
+			rows = append(rows, &row{title, raw.Percentile(p), chalk.Cyan})
+			continue
 		}
 
-		if _, ok := g.r.FailedResponseStatusCode[url]; ok {
-			for statusCode, count := range g.r.FailedResponseStatusCode[url] {
-				g.addColoredRow(urlTable, chalk.Red, fmt.Sprintf("Response with status code %d", statusCode), count)
+		rows = append(rows,
+			&row{title + " (raw)", raw.Percentile(p), chalk.Cyan},
+			&row{title + " (corrected)", corrected.Percentile(p), chalk.Magenta})
+	}
+
+	return rows
+}
+
+func percentileName(p float64) string {
+	return fmt.Sprintf("p%s", strconv.FormatFloat(p, 'f', -1, 64))
+}
+
+func (g *tableGenerator) getPhaseTimesRows(url string) []*row {
+	phases, ok := g.r.PhaseTimes[url]
+
+	if !ok || g.r.PhaseTimesCount[url] == 0 {
+		return []*row{}
+	}
+
+	count := time.Duration(g.r.PhaseTimesCount[url])
+
+	return []*row{
+		{"Average DNS lookup time", phases.DNS / count, chalk.Cyan},
+		{"Average connect time", phases.Connect / count, chalk.Cyan},
+		{"Average TLS handshake time", phases.TLSHandshake / count, chalk.Cyan},
+		{"Average time to first byte", phases.TimeToFirstByte / count, chalk.Cyan},
+		{"Average download time", phases.Download / count, chalk.Cyan},
+	}
+}
+
+// getErrorTable returns the errors of the requests by URL and error class
+// along with their sample messages.
+func (g *tableGenerator) getErrorTable() *termtables.Table {
+	if len(g.r.Errors) == 0 {
+		return nil
+	}
+
+	table := termtables.CreateTable()
+	table.AddTitle(g.getColoredString("Errors", chalk.Red))
+	table.AddHeaders(g.getColoredString("URL", chalk.Cyan),
+		g.getColoredString("Error", chalk.Cyan),
+		g.getColoredString("Count", chalk.Red),
+		g.getColoredString("Samples", chalk.Cyan))
+
+	for _, url := range g.getURLs() {
+		for _, class := range g.getErrorClasses(url) {
+			errorResult := g.r.Errors[url][class]
+
+			table.AddRow(g.getColoredString(url, chalk.Cyan),
+				g.getColoredString(class, chalk.Cyan),
+				g.getColoredString(errorResult.Count, chalk.Red),
+				g.getColoredString(strings.Join(errorResult.Samples, " | "), chalk.Cyan))
+		}
+	}
+
+	return table
+}
+
+// getErrorClasses returns the sorted error classes of a URL.
+func (g *tableGenerator) getErrorClasses(url string) []string {
+	classes := make([]string, 0, len(g.r.Errors[url]))
+
+	for class := range g.r.Errors[url] {
+		classes = append(classes, class)
+	}
+
+	sort.Strings(classes)
+
+	return classes
+}
+
+func (g *tableGenerator) getStageTable() *termtables.Table {
+	if len(g.r.StageResult) == 0 {
+		return nil
+	}
+
+	table := termtables.CreateTable()
+	table.AddTitle(g.getColoredString("Result for load stages", chalk.Blue))
+	table.AddHeaders(g.getColoredString("Stage", chalk.Cyan),
+		g.getColoredString("Total", chalk.Cyan),
+		g.getColoredString("Success", chalk.Green),
+		g.getColoredString("Failed", chalk.Red),
+		g.getColoredString("Timedout", chalk.Yellow),
+		g.getColoredString("Average response time", chalk.Cyan))
+
+	for _, stageResult := range g.r.StageResult {
+		averageResponseTime := time.Duration(0)
+
+		if stageResult.ResponseTimesCount > 0 {
+			averageResponseTime = time.Duration(stageResult.TotalResponseTime.Nanoseconds() / int64(stageResult.ResponseTimesCount))
+		}
+
+		table.AddRow(g.getColoredString(stageResult.Name, chalk.Cyan),
+			g.getColoredString(stageResult.TotalRequests, chalk.Cyan),
+			g.getColoredString(stageResult.SuccessfulRequests, chalk.Green),
+			g.getColoredString(stageResult.FailedRequests, chalk.Red),
+			g.getColoredString(stageResult.TimedOutRequests, chalk.Yellow),
+			g.getColoredString(averageResponseTime, chalk.Cyan))
+	}
+
+	return table
+}
+
+// getScenarios returns the names of the scenarios in a stable order.
+func (g *tableGenerator) getScenarios() []string {
+	scenarios := make([]string, 0, len(g.r.ScenarioResult))
+
+	for scenario := range g.r.ScenarioResult {
+		scenarios = append(scenarios, scenario)
+	}
+
+	sort.Strings(scenarios)
+
+	return scenarios
+}
+
+func (g *tableGenerator) getScenarioRows(scenario string) []*row {
+	result := g.r.ScenarioResult[scenario]
+	successRate := float64(0)
+	averageTime := time.Duration(0)
+
+	if result.Iterations > 0 {
+		successRate = float64(result.SuccessfulIterations*100) / float64(result.Iterations)
+		averageTime = time.Duration(result.TotalTime.Nanoseconds() / int64(result.Iterations))
+	}
+
+	rows := []*row{
+		{"Iterations", result.Iterations, chalk.Cyan},
+		{"Successful iterations", result.SuccessfulIterations, chalk.Green},
+		{"Failed iterations", result.FailedIterations, chalk.Red},
+		{"Success rate", fmt.Sprintf("%%%.2f", successRate), chalk.Green},
+		{"Shortest iteration time", result.ShortestTime, chalk.Cyan},
+		{"Longest iteration time", result.LongestTime, chalk.Cyan},
+		{"Average iteration time", averageTime, chalk.Cyan},
+	}
+
+	if result.TimeHistogram != nil && result.TimeHistogram.TotalCount > 0 {
+		for _, p := range g.percentiles {
+			rows = append(rows, &row{fmt.Sprintf("%s iteration time", percentileName(p)), result.TimeHistogram.Percentile(p), chalk.Cyan})
+		}
+	}
+
+	return rows
+}
+
+// getScenarioStepHeaders and getScenarioStepValues describe the table of the
+// steps of a scenario. The requests of a step are stored as the requests of a
+// URL named after the step.
+func getScenarioStepHeaders() []string {
+	return []string{"Step", "Total", "Success", "Failed", "Timedout", "Average response time", "Failed iterations"}
+}
+
+func (g *tableGenerator) getScenarioStepValues(scenario, step string) []interface{} {
+	successful, failed := 0, g.r.FailedResponse[step]
+	averageResponseTime := time.Duration(0)
+
+	for _, count := range g.r.ResponseStatusCode[step] {
+		successful += count
+	}
+
+	for _, count := range g.r.FailedResponseStatusCode[step] {
+		failed += count
+	}
+
+	if g.r.ResponseTimesCount[step] > 0 {
+		averageResponseTime = time.Duration(g.r.ResponseTime[step].Nanoseconds() / int64(g.r.ResponseTimesCount[step]))
+	}
+
+	timedOut := g.r.TimedoutResponse[step]
+
+	return []interface{}{
+		step,
+		successful + failed + timedOut,
+		successful,
+		failed,
+		timedOut,
+		averageResponseTime,
+		g.r.ScenarioResult[scenario].FailedSteps[step],
+	}
+}
+
+func (g *tableGenerator) getScenarioTables() []*termtables.Table {
+	tables := make([]*termtables.Table, 0)
+
+	for _, scenario := range g.getScenarios() {
+		table := termtables.CreateTable()
+		table.AddTitle(g.getColoredString(fmt.Sprintf("Result for scenario %s", scenario), chalk.Blue))
+		g.addRows(table, g.getScenarioRows(scenario))
+
+		stepTable := termtables.CreateTable()
+		stepTable.AddTitle(g.getColoredString(fmt.Sprintf("Steps of scenario %s", scenario), chalk.Blue))
+
+		headers := []interface{}{}
+
+		for _, header := range getScenarioStepHeaders() {
+			headers = append(headers, g.getColoredString(header, chalk.Cyan))
+		}
+
+		stepTable.AddHeaders(headers...)
+
+		for _, step := range g.r.ScenarioResult[scenario].Steps {
+			g.addColoredRow(stepTable, chalk.Cyan, g.getScenarioStepValues(scenario, step)...)
+		}
+
+		tables = append(tables, table, stepTable)
+	}
+
+	return tables
+}
+
+func (g *tableGenerator) getTimeSeriesTables() []*termtables.Table {
+	timeSeriesTables := make([]*termtables.Table, 0)
+
+	if g.r.TimeSeriesInterval <= 0 {
+		return timeSeriesTables
+	}
+
+	urls := make([]string, 0, len(g.r.TimeSeries))
+
+	for url := range g.r.TimeSeries {
+		urls = append(urls, url)
+	}
+
+	sort.Strings(urls)
+
+	for _, url := range urls {
+		table := termtables.CreateTable()
+		table.AddTitle(g.getColoredString(fmt.Sprintf("Time series for %s", url), chalk.Blue))
+
+		headers := []interface{}{
+			g.getColoredString("Time", chalk.Cyan),
+			g.getColoredString("Sent", chalk.Cyan),
+			g.getColoredString("2xx", chalk.Green),
+			g.getColoredString("3xx", chalk.Cyan),
+			g.getColoredString("4xx", chalk.Red),
+			g.getColoredString("5xx", chalk.Red),
+			g.getColoredString("Failed", chalk.Red),
+			g.getColoredString("Timedout", chalk.Yellow),
+			g.getColoredString("Data", chalk.Cyan),
+		}
+
+		for _, p := range g.percentiles {
+			headers = append(headers, g.getColoredString(percentileName(p), chalk.Cyan))
+		}
+
+		table.AddHeaders(headers...)
+
+		for index, bucket := range g.r.TimeSeries[url] {
+			row := []interface{}{
+				g.getColoredString(time.Duration(index)*g.r.TimeSeriesInterval, chalk.Cyan),
+				g.getColoredString(bucket.SentRequests, chalk.Cyan),
+				g.getColoredString(bucket.StatusClasses["2xx"], chalk.Green),
+				g.getColoredString(bucket.StatusClasses["3xx"], chalk.Cyan),
+				g.getColoredString(bucket.StatusClasses["4xx"], chalk.Red),
+				g.getColoredString(bucket.StatusClasses["5xx"], chalk.Red),
+				g.getColoredString(bucket.FailedRequests, chalk.Red),
+				g.getColoredString(bucket.TimedOutRequests, chalk.Yellow),
+				g.getColoredString(fmt.Sprintf("%.2f KB", float64(bucket.ReceivedDataLength)/1024), chalk.Cyan),
 			}
+
+			for _, p := range g.percentiles {
+				row = append(row, g.getColoredString(bucket.Histogram.Percentile(p), chalk.Cyan))
+			}
+
+			table.AddRow(row...)
 		}
 
-		averageResponseTime := time.Duration(g.r.ResponseTime[url].Nanoseconds() / int64(g.r.ResponseTimesCount[url]))
+		timeSeriesTables = append(timeSeriesTables, table)
+	}
 
-		g.addColoredRow(urlTable, chalk.Red, "Failed requests", g.r.FailedResponse[url])
-		g.addColoredRow(urlTable, chalk.Yellow, "Timedout requests", g.r.TimedoutResponse[url])
-		g.addColoredRow(urlTable, chalk.Cyan, "Sum response times", g.r.ResponseTime[url])
-		g.addColoredRow(urlTable, chalk.Cyan, "Shortest response time", g.r.ShortestResponseTimes[url])
-		g.addColoredRow(urlTable, chalk.Cyan, "Longest response time", g.r.LongestResponseTimes[url])
-		g.addColoredRow(urlTable, chalk.Cyan, "Average response time", averageResponseTime)
+	return timeSeriesTables
+}
 
-		urlTables = append(urlTables, urlTable)
+// getConcurrencyTable returns a single table with a row for each URL in each
+// batch of concurrent requests or time window, so that long benchmarks do not
+// print a table per window.
+func (g *tableGenerator) getConcurrencyTable() *termtables.Table {
+	rows := g.getConcurrencyRows()
+
+	if len(rows) == 0 {
+		return nil
 	}
 
-	return urlTables
+	table := termtables.CreateTable()
+	table.AddTitle(g.getColoredString(g.getConcurrencyTitle(), chalk.Blue))
+	table.AddHeaders(g.getColoredString(g.getConcurrencyHeader(), chalk.Cyan),
+		g.getColoredString("URL", chalk.Cyan),
+		g.getColoredString("Total", chalk.Cyan),
+		g.getColoredString("Success", chalk.Green),
+		g.getColoredString("Failed", chalk.Red),
+		g.getColoredString("Timedout", chalk.Yellow))
+
+	for _, row := range rows {
+		table.AddRow(g.getColoredString(row.label, chalk.Cyan),
+			g.getColoredString(row.url, chalk.Cyan),
+			g.getColoredString(row.result.TotalRequests, chalk.Cyan),
+			g.getColoredString(row.result.SuccessfulRequests, chalk.Green),
+			g.getColoredString(row.result.FailedRequests, chalk.Red),
+			g.getColoredString(row.result.TimedOutRequests, chalk.Yellow))
+	}
+
+	return table
 }
 
-func (g *tableGenerator) getConcurrencyTables() map[int]*termtables.Table {
-	concurrencyTables := make(map[int]*termtables.Table)
+type concurrencyRow struct {
+	label, url string
+	result     *report.ConcurrencyResult
+}
+
+// getConcurrencyRows returns the concurrency results ordered by batch or time
+// window and then by URL.
+func (g *tableGenerator) getConcurrencyRows() []*concurrencyRow {
+	rows := []*concurrencyRow{}
+	urls := g.getURLs()
+
+	for index := 0; ; index++ {
+		found := false
 
-	for url, concurrencyResults := range g.r.ConcurrencyResult {
-		for index, concurrencyResult := range concurrencyResults {
-			if _, ok := concurrencyTables[index]; !ok {
-				concurrencyTables[index] = termtables.CreateTable()
-				concurrencyTables[index].AddTitle(g.getColoredString(fmt.Sprintf("Result for concurrent requests batch %d", index+1), chalk.Blue))
-				concurrencyTables[index].AddHeaders(g.getColoredString("URL", chalk.Cyan))
-				concurrencyTables[index].AddHeaders(g.getColoredString("Total", chalk.Cyan))
-				concurrencyTables[index].AddHeaders(g.getColoredString("Success", chalk.Green))
-				concurrencyTables[index].AddHeaders(g.getColoredString("Failed", chalk.Red))
-				concurrencyTables[index].AddHeaders(g.getColoredString("Timedout", chalk.Yellow))
+		for _, url := range urls {
+			if index < len(g.r.ConcurrencyResult[url]) {
+				rows = append(rows, &concurrencyRow{g.getConcurrencyLabel(index), url, g.r.ConcurrencyResult[url][index]})
+				found = true
 			}
+		}
 
-			concurrencyTables[index].AddRow(g.getColoredString(url, chalk.Cyan),
-				g.getColoredString(concurrencyResult.TotalRequests, chalk.Cyan),
-				g.getColoredString(concurrencyResult.SuccessfulRequests, chalk.Green),
-				g.getColoredString(concurrencyResult.FailedRequests, chalk.Red),
-				g.getColoredString(concurrencyResult.TimedOutRequests, chalk.Yellow))
+		if !found {
+			return rows
 		}
 	}
+}
 
-	return concurrencyTables
+func (g *tableGenerator) getConcurrencyTitle() string {
+	if g.r.ConcurrencyWindow > 0 {
+		return fmt.Sprintf("Result for time windows of %v", g.r.ConcurrencyWindow)
+	}
+
+	return "Result for concurrent requests batches"
+}
+
+func (g *tableGenerator) getConcurrencyHeader() string {
+	if g.r.ConcurrencyWindow > 0 {
+		return "Time window"
+	}
+
+	return "Batch"
+}
+
+func (g *tableGenerator) getConcurrencyLabel(index int) string {
+	if g.r.ConcurrencyWindow > 0 {
+		return fmt.Sprintf("%v - %v", time.Duration(index)*g.r.ConcurrencyWindow, time.Duration(index+1)*g.r.ConcurrencyWindow)
+	}
+
+	return fmt.Sprint(index + 1)
+}
+
+func (g *tableGenerator) addRows(table *termtables.Table, rows []*row) {
+	for _, row := range rows {
+		g.addColoredRow(table, row.color, row.title, row.value)
+	}
 }
 
 func (g *tableGenerator) addColoredRow(table *termtables.Table, color chalk.Color, values ...interface{}) {
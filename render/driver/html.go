@@ -0,0 +1,116 @@
+package driver
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+
+	"github.com/sasanrose/gbench/render"
+	"github.com/sasanrose/gbench/report"
+)
+
+const defaultAddress = "localhost:8080"
+
+type html struct {
+	address     string
+	output      io.Writer
+	percentiles []float64
+}
+
+// NewHTML creates a new html renderer which serves the benchmark report over
+// http. A config can be created on the fly or using the predefined functions.
+func NewHTML(configurations ...func(*html)) render.Renderer {
+	r := &html{address: defaultAddress, output: os.Stdout, percentiles: defaultPercentiles}
+
+	for _, config := range configurations {
+		config(r)
+	}
+
+	return r
+}
+
+// WithAddress creates a config to set the address to serve the report on
+// (i.e. localhost:8080).
+func WithAddress(address string) func(*html) {
+	return func(r *html) {
+		r.address = address
+	}
+}
+
+// WithHTMLPercentiles creates a config to set the response time percentiles
+// to render (i.e. 50, 99 or 99.9).
+func WithHTMLPercentiles(percentiles []float64) func(*html) {
+	return func(r *html) {
+		r.percentiles = percentiles
+	}
+}
+
+// Render serves the report until the server fails.
+func (r *html) Render(result *report.Result) error {
+	handler, err := r.getHandler(result)
+
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(r.output, "Serving the report on http://%s\n", r.address)
+
+	return http.ListenAndServe(r.address, handler)
+}
+
+// getHandler renders the page once and returns a handler which serves it
+// along with the raw report.
+func (r *html) getHandler(result *report.Result) (http.Handler, error) {
+	page := &bytes.Buffer{}
+
+	if err := writeHTMLPage(page, result, r.percentiles); err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(result)
+
+	if err != nil {
+		return nil, err
+	}
+
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/" {
+			http.NotFound(w, req)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write(page.Bytes())
+	})
+
+	mux.HandleFunc("/report.json", func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(data)
+	})
+
+	return mux, nil
+}
+
+type htmlFile struct {
+	*html
+}
+
+// NewHTMLFile creates a new html renderer which writes the benchmark report to
+// output as a single self-contained page. The html configs can be used to
+// configure it.
+func NewHTMLFile(output io.Writer, configurations ...func(*html)) render.Renderer {
+	r := NewHTML(configurations...).(*html)
+	r.output = output
+
+	return &htmlFile{r}
+}
+
+// Render writes the report page to the output.
+func (r *htmlFile) Render(result *report.Result) error {
+	return writeHTMLPage(r.output, result, r.percentiles)
+}
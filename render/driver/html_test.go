@@ -0,0 +1,147 @@
+package driver
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sasanrose/gbench/render"
+	"github.com/sasanrose/gbench/report"
+)
+
+func TestNewHTML(t *testing.T) {
+	r := NewHTML()
+
+	if _, ok := r.(render.Renderer); !ok {
+		t.Fatal("Expected to get a var of Renderer interface type")
+	}
+
+	if r.(*html).address != defaultAddress {
+		t.Errorf("Expected default address but got %s", r.(*html).address)
+	}
+
+	r = NewHTML(WithAddress("0.0.0.0:7777"), WithHTMLPercentiles([]float64{99.9}))
+
+	if h := r.(*html); h.address != "0.0.0.0:7777" || len(h.percentiles) != 1 || h.percentiles[0] != 99.9 {
+		t.Errorf("Unexpected configuration: %+v", h)
+	}
+}
+
+func TestHTMLHandler(t *testing.T) {
+	result := &report.Result{}
+	result.Init(2)
+	result.SetStartTime(time.Now())
+	result.SetTimeSeriesInterval(time.Second)
+
+	addTestData(result)
+
+	r := &html{output: &bytes.Buffer{}, percentiles: []float64{50, 99.9}}
+	handler, err := r.getHandler(result)
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	ts := httptest.NewServer(handler)
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL)
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	body, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	if !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/html") {
+		t.Errorf("Unexpected content type: %s", resp.Header.Get("Content-Type"))
+	}
+
+	expected := []string{
+		"<style>",
+		"Final benchmark result",
+		"Total successful requests",
+		"Final result for http://testurl1.com",
+		"Response with status code 500",
+		"p99.9 response time",
+		"<svg",
+		"Time series for http://testurl3.com",
+		"Result for load stages",
+		"Result for concurrent requests batches",
+		"<script>",
+	}
+
+	for _, str := range expected {
+		if !strings.Contains(string(body), str) {
+			t.Errorf("Could not find %s in the page", str)
+		}
+	}
+
+	if strings.Contains(string(body), "src=\"http") || strings.Contains(string(body), "href=\"http") {
+		t.Error("Expected the page not to load external assets")
+	}
+
+	resp, err = http.Get(ts.URL + "/report.json")
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	decoded := &report.Result{}
+
+	if err := json.NewDecoder(resp.Body).Decode(decoded); err != nil {
+		t.Errorf("Could not decode the report: %v", err)
+	}
+
+	resp.Body.Close()
+
+	if decoded.TotalRequests != result.TotalRequests {
+		t.Errorf("Expected %d total requests but got %d", result.TotalRequests, decoded.TotalRequests)
+	}
+
+	resp, _ = http.Get(ts.URL + "/missing")
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected not found but got %d", resp.StatusCode)
+	}
+}
+
+func TestHTMLFile(t *testing.T) {
+	result := &report.Result{}
+	result.Init(2)
+
+	addTestData(result)
+	result.AddScenario("checkout", []string{"http://testurl1.com"})
+	result.AddScenarioResult("checkout", time.Millisecond, "")
+	result.AddError("http://testurl2.com", report.ErrorConnectionRefused, "connect: connection refused")
+
+	buf := &bytes.Buffer{}
+	r := NewHTMLFile(buf, WithHTMLPercentiles([]float64{50, 99.9}))
+
+	if _, ok := r.(render.Renderer); !ok {
+		t.Fatal("Expected to get a var of Renderer interface type")
+	}
+
+	if err := r.Render(result); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	page := buf.String()
+
+	for _, str := range append(expectedStringsInOutput, "<style>", "<script>", "Concurrency results for http://testurl2.com", "Success at batch 3", "Result for scenario checkout", "Steps of scenario checkout", "href=\"#errors\"", "connection-refused", "connect: connection refused") {
+		if !strings.Contains(page, str) {
+			t.Errorf("Could not find %s in the page", str)
+		}
+	}
+
+	if strings.Contains(page, "src=") || strings.Contains(page, "<link") {
+		t.Error("Expected the page to be self-contained")
+	}
+}
@@ -0,0 +1,391 @@
+package driver
+
+import (
+	"embed"
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/sasanrose/gbench/report"
+	"github.com/ttacon/chalk"
+)
+
+//go:embed assets
+var assets embed.FS
+
+var pageTemplate = template.Must(template.ParseFS(assets, "assets/report.html"))
+
+// htmlPage contains everything needed to render the html report. Styles and
+// scripts are inlined so that the page works offline and as a single file.
+type htmlPage struct {
+	Style             template.CSS
+	Script            template.JS
+	Summary           []*htmlRow
+	SummaryChart      template.HTML
+	URLs              []*htmlURLSection
+	Errors            *htmlTable
+	Stages            *htmlTable
+	Scenarios         []*htmlScenarioSection
+	Concurrency       *htmlTable
+	ConcurrencyCharts []*htmlChart
+}
+
+type htmlChart struct {
+	Title string
+	Chart template.HTML
+}
+
+type htmlRow struct {
+	Title, Value, Class string
+}
+
+type htmlTable struct {
+	Title   string
+	Headers []string
+	Rows    [][]string
+}
+
+type htmlURLSection struct {
+	ID              string
+	URL             string
+	Rows            []*htmlRow
+	StatusChart     template.HTML
+	PercentileChart template.HTML
+	ThroughputChart template.HTML
+	LatencyChart    template.HTML
+	TimeSeries      *htmlTable
+}
+
+type htmlScenarioSection struct {
+	ID       string
+	Scenario string
+	Rows     []*htmlRow
+	Steps    *htmlTable
+}
+
+// writeHTMLPage renders the html report of the given result to w.
+func writeHTMLPage(w io.Writer, result *report.Result, percentiles []float64) error {
+	page, err := newHTMLPage(result, percentiles)
+
+	if err != nil {
+		return err
+	}
+
+	return pageTemplate.Execute(w, page)
+}
+
+func newHTMLPage(result *report.Result, percentiles []float64) (*htmlPage, error) {
+	style, err := assets.ReadFile("assets/report.css")
+
+	if err != nil {
+		return nil, err
+	}
+
+	script, err := assets.ReadFile("assets/report.js")
+
+	if err != nil {
+		return nil, err
+	}
+
+	g := &tableGenerator{r: result, percentiles: percentiles}
+
+	page := &htmlPage{
+		Style:   template.CSS(style),
+		Script:  template.JS(script),
+		Summary: getHTMLRows(g.getBenchResultRows()),
+		SummaryChart: getBarChart([]*bar{
+			{"Successful", float64(result.SuccessfulRequests), fmt.Sprint(result.SuccessfulRequests), "success"},
+			{"Failed", float64(result.FailedRequests), fmt.Sprint(result.FailedRequests), "failure"},
+			{"Timedout", float64(result.TimedOutRequests), fmt.Sprint(result.TimedOutRequests), "timedout"},
+			{"Dropped", float64(result.DroppedRequests), fmt.Sprint(result.DroppedRequests), "dropped"},
+		}),
+		Errors:            g.getHTMLErrorTable(),
+		Stages:            g.getHTMLStageTable(),
+		Concurrency:       g.getHTMLConcurrencyTable(),
+		ConcurrencyCharts: g.getHTMLConcurrencyCharts(),
+	}
+
+	for index, url := range g.getURLs() {
+		page.URLs = append(page.URLs, g.getHTMLURLSection(index, url))
+	}
+
+	for index, scenario := range g.getScenarios() {
+		page.Scenarios = append(page.Scenarios, g.getHTMLScenarioSection(index, scenario))
+	}
+
+	return page, nil
+}
+
+func getHTMLRows(rows []*row) []*htmlRow {
+	htmlRows := make([]*htmlRow, 0, len(rows))
+
+	for _, row := range rows {
+		htmlRows = append(htmlRows, &htmlRow{Title: row.title, Value: fmt.Sprint(row.value), Class: getColorClass(row.color)})
+	}
+
+	return htmlRows
+}
+
+// getColorClass maps the colors used by the cli to css classes.
+func getColorClass(color chalk.Color) string {
+	switch color {
+	case chalk.Green:
+		return "success"
+	case chalk.Red:
+		return "failure"
+	case chalk.Yellow:
+		return "timedout"
+	case chalk.Magenta:
+		return "dropped"
+	}
+
+	return ""
+}
+
+func (g *tableGenerator) getHTMLURLSection(index int, url string) *htmlURLSection {
+	section := &htmlURLSection{
+		ID:   fmt.Sprintf("url-%d", index+1),
+		URL:  url,
+		Rows: getHTMLRows(g.getURLRows(url)),
+	}
+
+	bars := []*bar{}
+
+	for _, statusCode := range sortedStatusCodes(g.r.ResponseStatusCode[url]) {
+		count := g.r.ResponseStatusCode[url][statusCode]
+		bars = append(bars, &bar{fmt.Sprint(statusCode), float64(count), fmt.Sprint(count), "success"})
+	}
+
+	for _, statusCode := range sortedStatusCodes(g.r.FailedResponseStatusCode[url]) {
+		count := g.r.FailedResponseStatusCode[url][statusCode]
+		bars = append(bars, &bar{fmt.Sprint(statusCode), float64(count), fmt.Sprint(count), "failure"})
+	}
+
+	if count := g.r.FailedResponse[url]; count > 0 {
+		bars = append(bars, &bar{"Failed", float64(count), fmt.Sprint(count), "failure"})
+	}
+
+	if count := g.r.TimedoutResponse[url]; count > 0 {
+		bars = append(bars, &bar{"Timedout", float64(count), fmt.Sprint(count), "timedout"})
+	}
+
+	section.StatusChart = getBarChart(bars)
+
+	if histogram := g.r.Histograms[url]; histogram != nil && histogram.TotalCount > 0 {
+		bars = []*bar{}
+
+		for _, p := range g.percentiles {
+			value := histogram.Percentile(p)
+			bars = append(bars, &bar{percentileName(p), toMilliseconds(value), value.String(), ""})
+		}
+
+		section.PercentileChart = getBarChart(bars)
+	}
+
+	g.addHTMLTimeSeries(section, url)
+
+	return section
+}
+
+func (g *tableGenerator) addHTMLTimeSeries(section *htmlURLSection, url string) {
+	buckets := g.r.TimeSeries[url]
+
+	if g.r.TimeSeriesInterval <= 0 || len(buckets) == 0 {
+		return
+	}
+
+	table := &htmlTable{
+		Title:   fmt.Sprintf("Time series for %s", url),
+		Headers: []string{"Time", "Sent", "2xx", "3xx", "4xx", "5xx", "Failed", "Timedout", "Data"},
+	}
+
+	sent := &series{name: "Sent", points: make([]float64, len(buckets))}
+	successful := &series{name: "2xx", class: "success", points: make([]float64, len(buckets))}
+	errors := &series{name: "Errors", class: "failure", points: make([]float64, len(buckets))}
+	latencies := make([]*series, len(g.percentiles))
+
+	for i, p := range g.percentiles {
+		table.Headers = append(table.Headers, percentileName(p))
+		latencies[i] = &series{name: percentileName(p), class: fmt.Sprintf("series-%d", i+1), points: make([]float64, len(buckets))}
+	}
+
+	for index, bucket := range buckets {
+		row := []string{
+			fmt.Sprint(time.Duration(index) * g.r.TimeSeriesInterval),
+			fmt.Sprint(bucket.SentRequests),
+			fmt.Sprint(bucket.StatusClasses["2xx"]),
+			fmt.Sprint(bucket.StatusClasses["3xx"]),
+			fmt.Sprint(bucket.StatusClasses["4xx"]),
+			fmt.Sprint(bucket.StatusClasses["5xx"]),
+			fmt.Sprint(bucket.FailedRequests),
+			fmt.Sprint(bucket.TimedOutRequests),
+			fmt.Sprintf("%.2f KB", float64(bucket.ReceivedDataLength)/1024),
+		}
+
+		sent.points[index] = float64(bucket.SentRequests)
+		successful.points[index] = float64(bucket.StatusClasses["2xx"])
+		errors.points[index] = float64(bucket.StatusClasses["4xx"] + bucket.StatusClasses["5xx"] + bucket.FailedRequests + bucket.TimedOutRequests)
+
+		for i, p := range g.percentiles {
+			value := bucket.Histogram.Percentile(p)
+			row = append(row, fmt.Sprint(value))
+			latencies[i].points[index] = toMilliseconds(value)
+		}
+
+		table.Rows = append(table.Rows, row)
+	}
+
+	section.TimeSeries = table
+	section.ThroughputChart = getLineChart([]*series{sent, successful, errors}, getIntervalLabel(g.r.TimeSeriesInterval), "")
+	section.LatencyChart = getLineChart(latencies, getIntervalLabel(g.r.TimeSeriesInterval), "ms")
+}
+
+func (g *tableGenerator) getHTMLErrorTable() *htmlTable {
+	if len(g.r.Errors) == 0 {
+		return nil
+	}
+
+	table := &htmlTable{
+		Title:   "Errors",
+		Headers: []string{"URL", "Error", "Count", "Samples"},
+	}
+
+	for _, url := range g.getURLs() {
+		for _, class := range g.getErrorClasses(url) {
+			errorResult := g.r.Errors[url][class]
+
+			table.Rows = append(table.Rows, []string{
+				url,
+				class,
+				fmt.Sprint(errorResult.Count),
+				strings.Join(errorResult.Samples, " | "),
+			})
+		}
+	}
+
+	return table
+}
+
+func (g *tableGenerator) getHTMLStageTable() *htmlTable {
+	if len(g.r.StageResult) == 0 {
+		return nil
+	}
+
+	table := &htmlTable{
+		Title:   "Result for load stages",
+		Headers: []string{"Stage", "Total", "Success", "Failed", "Timedout", "Average response time"},
+	}
+
+	for _, stageResult := range g.r.StageResult {
+		averageResponseTime := time.Duration(0)
+
+		if stageResult.ResponseTimesCount > 0 {
+			averageResponseTime = time.Duration(stageResult.TotalResponseTime.Nanoseconds() / int64(stageResult.ResponseTimesCount))
+		}
+
+		table.Rows = append(table.Rows, []string{
+			stageResult.Name,
+			fmt.Sprint(stageResult.TotalRequests),
+			fmt.Sprint(stageResult.SuccessfulRequests),
+			fmt.Sprint(stageResult.FailedRequests),
+			fmt.Sprint(stageResult.TimedOutRequests),
+			fmt.Sprint(averageResponseTime),
+		})
+	}
+
+	return table
+}
+
+func (g *tableGenerator) getHTMLScenarioSection(index int, scenario string) *htmlScenarioSection {
+	steps := &htmlTable{
+		Title:   fmt.Sprintf("Steps of scenario %s", scenario),
+		Headers: getScenarioStepHeaders(),
+	}
+
+	for _, step := range g.r.ScenarioResult[scenario].Steps {
+		values := []string{}
+
+		for _, value := range g.getScenarioStepValues(scenario, step) {
+			values = append(values, fmt.Sprint(value))
+		}
+
+		steps.Rows = append(steps.Rows, values)
+	}
+
+	return &htmlScenarioSection{
+		ID:       fmt.Sprintf("scenario-%d", index+1),
+		Scenario: scenario,
+		Rows:     getHTMLRows(g.getScenarioRows(scenario)),
+		Steps:    steps,
+	}
+}
+
+func (g *tableGenerator) getHTMLConcurrencyTable() *htmlTable {
+	rows := g.getConcurrencyRows()
+
+	if len(rows) == 0 {
+		return nil
+	}
+
+	table := &htmlTable{
+		Title:   g.getConcurrencyTitle(),
+		Headers: []string{g.getConcurrencyHeader(), "URL", "Total", "Success", "Failed", "Timedout"},
+	}
+
+	for _, row := range rows {
+		table.Rows = append(table.Rows, []string{
+			row.label,
+			row.url,
+			fmt.Sprint(row.result.TotalRequests),
+			fmt.Sprint(row.result.SuccessfulRequests),
+			fmt.Sprint(row.result.FailedRequests),
+			fmt.Sprint(row.result.TimedOutRequests),
+		})
+	}
+
+	return table
+}
+
+// getHTMLConcurrencyCharts draws the successful, failed and timed out requests
+// of each URL through the concurrency batches or time windows.
+func (g *tableGenerator) getHTMLConcurrencyCharts() []*htmlChart {
+	charts := []*htmlChart{}
+	label := func(index int) string {
+		return fmt.Sprintf("batch %d", index+1)
+	}
+
+	if g.r.ConcurrencyWindow > 0 {
+		label = getIntervalLabel(g.r.ConcurrencyWindow)
+	}
+
+	for _, url := range g.getURLs() {
+		concurrencyResults := g.r.ConcurrencyResult[url]
+
+		if len(concurrencyResults) == 0 {
+			continue
+		}
+
+		successful := &series{name: "Success", class: "success", points: make([]float64, len(concurrencyResults))}
+		failed := &series{name: "Failed", class: "failure", points: make([]float64, len(concurrencyResults))}
+		timedOut := &series{name: "Timedout", class: "timedout", points: make([]float64, len(concurrencyResults))}
+
+		for index, concurrencyResult := range concurrencyResults {
+			successful.points[index] = float64(concurrencyResult.SuccessfulRequests)
+			failed.points[index] = float64(concurrencyResult.FailedRequests)
+			timedOut.points[index] = float64(concurrencyResult.TimedOutRequests)
+		}
+
+		charts = append(charts, &htmlChart{
+			Title: fmt.Sprintf("Concurrency results for %s", url),
+			Chart: getLineChart([]*series{successful, failed, timedOut}, label, ""),
+		})
+	}
+
+	return charts
+}
+
+func toMilliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
@@ -8,3 +8,9 @@ import "github.com/sasanrose/gbench/report"
 type Renderer interface {
 	Render(result *report.Result) error
 }
+
+// Comparer defines the interface of the drivers which compare the reports of
+// several benchmarks. The first report is the baseline of the comparison.
+type Comparer interface {
+	Compare(names []string, results []*report.Result) error
+}
@@ -0,0 +1,196 @@
+package report
+
+import (
+	"math"
+	"math/bits"
+	"sort"
+	"time"
+)
+
+// subBucketBits defines the precision of the histogram. Each power of two is
+// split into 2^subBucketBits linear sub buckets which bounds the relative
+// error of recorded values to 1/2^subBucketBits (less than 1%).
+const subBucketBits = 7
+
+const subBuckets = 1 << subBucketBits
+
+// Histogram is a log-linear histogram of durations in the spirit of HDR
+// histograms. It has a fixed relative error, its memory is bounded by the
+// number of powers of two between the smallest and the largest values (a few
+// thousand buckets at most) and histograms can be merged by adding up their
+// buckets.
+type Histogram struct {
+	// Counts of recorded values keyed by bucket index. Only non empty buckets
+	// are stored.
+	Counts     map[int]int64 `json:"counts"`
+	TotalCount int64         `json:"total-count"`
+	Min        time.Duration `json:"min"`
+	Max        time.Duration `json:"max"`
+}
+
+// NewHistogram creates an empty histogram.
+func NewHistogram() *Histogram {
+	return &Histogram{Counts: make(map[int]int64)}
+}
+
+// Record records a duration.
+func (h *Histogram) Record(d time.Duration) {
+	h.RecordN(d, 1)
+}
+
+// RecordN records a duration n times.
+func (h *Histogram) RecordN(d time.Duration, n int64) {
+	if n <= 0 {
+		return
+	}
+
+	if d < 0 {
+		d = 0
+	}
+
+	if h.TotalCount == 0 || d < h.Min {
+		h.Min = d
+	}
+
+	if d > h.Max {
+		h.Max = d
+	}
+
+	h.Counts[bucketIndex(d)] += n
+	h.TotalCount += n
+}
+
+// RecordCorrected records a duration and corrects it for coordinated
+// omission. When the duration is longer than the expected interval between
+// requests, the requests which would have been sent meanwhile are backfilled
+// with linearly decreasing durations. The backfilled durations are recorded a
+// bucket at a time, so a short interval does not slow down recording.
+func (h *Histogram) RecordCorrected(d, expectedInterval time.Duration) {
+	h.Record(d)
+
+	if expectedInterval <= 0 {
+		return
+	}
+
+	// The k-th backfilled duration is d - k*expectedInterval for k from 1 to
+	// missing.
+	missing := int64(d/expectedInterval) - 1
+
+	for k := int64(1); k <= missing; {
+		first := d - time.Duration(k)*expectedInterval
+		// The backfilled durations down to the lowest value of the bucket
+		// of the first one fall in the same bucket.
+		last := int64((d - lowestEquivalentValue(bucketIndex(first))) / expectedInterval)
+
+		if last > missing {
+			last = missing
+		}
+
+		h.Record(first)
+		h.RecordN(d-time.Duration(last)*expectedInterval, last-k)
+
+		k = last + 1
+	}
+}
+
+// Merge adds all the recorded values of another histogram.
+func (h *Histogram) Merge(other *Histogram) {
+	if other == nil || other.TotalCount == 0 {
+		return
+	}
+
+	if h.TotalCount == 0 || other.Min < h.Min {
+		h.Min = other.Min
+	}
+
+	if other.Max > h.Max {
+		h.Max = other.Max
+	}
+
+	for index, count := range other.Counts {
+		h.Counts[index] += count
+	}
+
+	h.TotalCount += other.TotalCount
+}
+
+// Percentile returns the value below which the given percentage of recorded
+// values fall (i.e. 99 for p99). The value is the highest value equivalent to
+// the bucket it falls in, which is never more than the recorded maximum.
+func (h *Histogram) Percentile(p float64) time.Duration {
+	if h == nil || h.TotalCount == 0 {
+		return 0
+	}
+
+	if p <= 0 {
+		return h.Min
+	}
+
+	target := int64(math.Ceil(p / 100 * float64(h.TotalCount)))
+
+	if target > h.TotalCount {
+		target = h.TotalCount
+	}
+
+	indexes := make([]int, 0, len(h.Counts))
+
+	for index := range h.Counts {
+		indexes = append(indexes, index)
+	}
+
+	sort.Ints(indexes)
+
+	var seen int64
+
+	for _, index := range indexes {
+		seen += h.Counts[index]
+
+		if seen >= target {
+			value := highestEquivalentValue(index)
+
+			if value > h.Max {
+				return h.Max
+			}
+
+			if value < h.Min {
+				return h.Min
+			}
+
+			return value
+		}
+	}
+
+	return h.Max
+}
+
+func bucketIndex(d time.Duration) int {
+	v := uint64(d)
+
+	if v < subBuckets {
+		return int(v)
+	}
+
+	shift := uint(bits.Len64(v) - subBucketBits - 1)
+
+	return int(shift+1)*subBuckets + int(v>>shift) - subBuckets
+}
+
+func lowestEquivalentValue(index int) time.Duration {
+	if index < subBuckets {
+		return time.Duration(index)
+	}
+
+	shift := uint(index/subBuckets - 1)
+
+	return time.Duration(uint64(subBuckets+index%subBuckets) << shift)
+}
+
+func highestEquivalentValue(index int) time.Duration {
+	if index < subBuckets {
+		return time.Duration(index)
+	}
+
+	shift := uint(index/subBuckets - 1)
+
+	return lowestEquivalentValue(index) + time.Duration(1<<shift) - 1
+}
@@ -0,0 +1,165 @@
+package report
+
+import (
+	"encoding/json"
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestHistogramBuckets(t *testing.T) {
+	previous := -1
+
+	for _, d := range []time.Duration{0, 1, 127, 128, 255, 256, 257, 1000, time.Millisecond, time.Second, time.Hour} {
+		index := bucketIndex(d)
+
+		if index < previous {
+			t.Errorf("Expected increasing bucket indexes but got %d after %d for %v", index, previous, d)
+		}
+
+		previous = index
+		highest := highestEquivalentValue(index)
+
+		if highest < d || float64(highest-d) > float64(d)/subBuckets {
+			t.Errorf("Highest equivalent value %v of %v is out of the error bound", highest, d)
+		}
+
+		if bucketIndex(highest) != index {
+			t.Errorf("Expected %v to be in the same bucket as %v", highest, d)
+		}
+	}
+}
+
+func TestHistogramPercentiles(t *testing.T) {
+	h := NewHistogram()
+	values := make([]time.Duration, 0)
+	random := rand.New(rand.NewSource(1))
+
+	for i := 0; i < 10000; i++ {
+		d := time.Duration(random.Int63n(int64(time.Second)))
+		values = append(values, d)
+		h.Record(d)
+	}
+
+	sort.Slice(values, func(i, j int) bool { return values[i] < values[j] })
+
+	for _, p := range []float64{50, 90, 99, 99.9} {
+		expected := values[int(p/100*float64(len(values)))-1]
+		actual := h.Percentile(p)
+
+		if actual < expected || float64(actual-expected) > float64(expected)/subBuckets {
+			t.Errorf("Expected p%v to be about %v but got %v", p, expected, actual)
+		}
+	}
+
+	if h.Percentile(0) != values[0] || h.Percentile(100) != values[len(values)-1] {
+		t.Errorf("Expected p0 and p100 to be the minimum and maximum but got %v and %v", h.Percentile(0), h.Percentile(100))
+	}
+
+	if len(h.Counts) > 30*subBuckets {
+		t.Errorf("Too many buckets: %d", len(h.Counts))
+	}
+}
+
+func TestHistogramRecordCorrected(t *testing.T) {
+	h := NewHistogram()
+
+	h.RecordCorrected(100*time.Millisecond, 10*time.Millisecond)
+	h.RecordCorrected(5*time.Millisecond, 10*time.Millisecond)
+
+	// 100ms is backfilled with 90ms, 80ms, ..., 10ms.
+	if h.TotalCount != 11 {
+		t.Errorf("Expected 11 values but got %d", h.TotalCount)
+	}
+
+	if h.Min != 5*time.Millisecond || h.Max != 100*time.Millisecond {
+		t.Errorf("Unexpected min and max: %v %v", h.Min, h.Max)
+	}
+
+	raw := NewHistogram()
+	raw.RecordCorrected(100*time.Millisecond, 0)
+
+	if raw.TotalCount != 1 {
+		t.Errorf("Expected no correction without an expected interval but got %d values", raw.TotalCount)
+	}
+}
+
+func TestHistogramRecordCorrectedBuckets(t *testing.T) {
+	for _, interval := range []time.Duration{time.Nanosecond, 7 * time.Nanosecond, 3 * time.Microsecond, 90 * time.Microsecond} {
+		h, expected := NewHistogram(), NewHistogram()
+		d := 300*time.Microsecond + 3
+
+		h.RecordCorrected(d, interval)
+		expected.Record(d)
+
+		for missing := d - interval; missing >= interval; missing -= interval {
+			expected.Record(missing)
+		}
+
+		if !reflect.DeepEqual(h, expected) {
+			t.Errorf("Unexpected corrected histogram with interval %s: %+v %+v", interval, h.TotalCount, expected.TotalCount)
+		}
+	}
+
+	h := NewHistogram()
+	start := time.Now()
+
+	h.RecordCorrected(time.Hour, time.Nanosecond)
+
+	if h.TotalCount != int64(time.Hour) || time.Since(start) > time.Second {
+		t.Errorf("Expected %d values to be recorded quickly but got %d in %s", int64(time.Hour), h.TotalCount, time.Since(start))
+	}
+}
+
+func TestHistogramMerge(t *testing.T) {
+	h1, h2, all := NewHistogram(), NewHistogram(), NewHistogram()
+
+	for i := 1; i <= 100; i++ {
+		d := time.Duration(i) * time.Millisecond
+
+		if i%2 == 0 {
+			h1.Record(d)
+		} else {
+			h2.Record(d)
+		}
+
+		all.Record(d)
+	}
+
+	h1.Merge(h2)
+	h1.Merge(nil)
+
+	if h1.TotalCount != all.TotalCount || h1.Min != all.Min || h1.Max != all.Max {
+		t.Errorf("Unexpected merged histogram: %d %v %v", h1.TotalCount, h1.Min, h1.Max)
+	}
+
+	for _, p := range []float64{50, 90, 99} {
+		if h1.Percentile(p) != all.Percentile(p) {
+			t.Errorf("Expected p%v of %v but got %v", p, all.Percentile(p), h1.Percentile(p))
+		}
+	}
+}
+
+func TestHistogramJSON(t *testing.T) {
+	h := NewHistogram()
+	h.Record(time.Millisecond)
+	h.RecordN(time.Second, 3)
+
+	encoded, err := json.Marshal(h)
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	decoded := &Histogram{}
+
+	if err := json.Unmarshal(encoded, decoded); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if decoded.TotalCount != 4 || decoded.Percentile(50) != h.Percentile(50) || decoded.Max != time.Second {
+		t.Errorf("Unexpected decoded histogram: %+v", decoded)
+	}
+}
@@ -0,0 +1,379 @@
+package report
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Merge merges another result into the result which should be initialized
+// with Init. Counters and the values of the URLs add up, shortest and longest
+// times combine and the start and end times take the widest range. Batches of
+// concurrent requests are concatenated while time windows and time series
+// buckets with the same index add up as the benchmarks are supposed to start
+// together.
+func (r *Result) Merge(other *Result) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if err := r.checkIntervals(other); err != nil {
+		return err
+	}
+
+	for url := range other.URLs {
+		r.URLs[url] = true
+	}
+
+	r.mergeCounters(other)
+	r.mergeTimes(other)
+	r.mergeResponseTimes(other)
+
+	mergeIntMaps(r.TimedoutResponse, other.TimedoutResponse)
+	mergeIntMaps(r.FailedResponse, other.FailedResponse)
+	mergeIntMaps(r.DroppedRequest, other.DroppedRequest)
+	mergeIntMaps(r.Redirects, other.Redirects)
+	mergeIntMaps(r.ReusedConnections, other.ReusedConnections)
+	mergeIntMaps(r.NewConnections, other.NewConnections)
+	mergeIntMaps(r.PhaseTimesCount, other.PhaseTimesCount)
+
+	for url, length := range other.ReceivedDataLength {
+		r.ReceivedDataLength[url] += length
+	}
+
+	mergeStatusCodes(r.ResponseStatusCode, other.ResponseStatusCode)
+	mergeStatusCodes(r.FailedResponseStatusCode, other.FailedResponseStatusCode)
+	mergeNestedIntMaps(r.Protocols, other.Protocols)
+	mergeNestedIntMaps(r.FailedAssertions, other.FailedAssertions)
+
+	r.mergeFinalURLs(other)
+	r.mergeErrors(other)
+	r.mergePhaseTimes(other)
+	r.mergeStageResults(other)
+	r.mergeScenarioResults(other)
+	r.mergeTimeSeries(other)
+	r.mergeConcurrencyResults(other)
+
+	return nil
+}
+
+// checkIntervals checks that the time series and the concurrency windows of
+// both results have the same intervals. The intervals of the other result are
+// taken when the result has none.
+func (r *Result) checkIntervals(other *Result) error {
+	if len(other.TimeSeries) > 0 && r.TimeSeriesInterval > 0 && r.TimeSeriesInterval != other.TimeSeriesInterval {
+		return fmt.Errorf("Time series intervals do not match: %s and %s", r.TimeSeriesInterval, other.TimeSeriesInterval)
+	}
+
+	if len(other.ConcurrencyResult) > 0 && len(r.ConcurrencyResult) > 0 && r.ConcurrencyWindow != other.ConcurrencyWindow {
+		return fmt.Errorf("Concurrency windows do not match: %s and %s", r.ConcurrencyWindow, other.ConcurrencyWindow)
+	}
+
+	if r.TimeSeriesInterval == 0 {
+		r.TimeSeriesInterval = other.TimeSeriesInterval
+	}
+
+	if len(r.ConcurrencyResult) == 0 {
+		r.ConcurrencyWindow = other.ConcurrencyWindow
+	}
+
+	return nil
+}
+
+func (r *Result) mergeCounters(other *Result) {
+	r.TotalReceivedDataLength += other.TotalReceivedDataLength
+	r.TotalRequests += other.TotalRequests
+	r.SuccessfulRequests += other.SuccessfulRequests
+	r.FailedRequests += other.FailedRequests
+	r.TimedOutRequests += other.TimedOutRequests
+	r.DroppedRequests += other.DroppedRequests
+}
+
+// mergeTimes takes the widest range of the start and end times. The total
+// time is the time between them when both are known.
+func (r *Result) mergeTimes(other *Result) {
+	if !other.StartTime.IsZero() && (r.StartTime.IsZero() || other.StartTime.Before(r.StartTime)) {
+		r.StartTime = other.StartTime
+	}
+
+	if other.EndTime.After(r.EndTime) {
+		r.EndTime = other.EndTime
+	}
+
+	if other.TotalTime > r.TotalTime {
+		r.TotalTime = other.TotalTime
+	}
+
+	if !r.StartTime.IsZero() && !r.EndTime.IsZero() {
+		r.TotalTime = r.EndTime.Sub(r.StartTime)
+	}
+
+	if r.StopReason == "" {
+		r.StopReason = other.StopReason
+	}
+}
+
+func (r *Result) mergeResponseTimes(other *Result) {
+	r.TotalResponseTime += other.TotalResponseTime
+	r.ResponseTimesTotalCount += other.ResponseTimesTotalCount
+	r.ShortestResponseTime = minDuration(r.ShortestResponseTime, other.ShortestResponseTime)
+
+	if other.LongestResponseTime > r.LongestResponseTime {
+		r.LongestResponseTime = other.LongestResponseTime
+	}
+
+	for url, responseTime := range other.ResponseTime {
+		r.ResponseTime[url] += responseTime
+	}
+
+	mergeIntMaps(r.ResponseTimesCount, other.ResponseTimesCount)
+
+	for url, shortest := range other.ShortestResponseTimes {
+		if current, ok := r.ShortestResponseTimes[url]; !ok || shortest < current {
+			r.ShortestResponseTimes[url] = shortest
+		}
+	}
+
+	for url, longest := range other.LongestResponseTimes {
+		if longest > r.LongestResponseTimes[url] {
+			r.LongestResponseTimes[url] = longest
+		}
+	}
+
+	r.Histogram.Merge(other.Histogram)
+	mergeHistograms(r.Histograms, other.Histograms)
+
+	if r.ExpectedInterval == 0 {
+		r.ExpectedInterval = other.ExpectedInterval
+	}
+
+	if other.CorrectedHistogram == nil {
+		return
+	}
+
+	if r.CorrectedHistogram == nil {
+		r.CorrectedHistogram = NewHistogram()
+		r.CorrectedHistograms = make(map[string]*Histogram)
+	}
+
+	r.CorrectedHistogram.Merge(other.CorrectedHistogram)
+	mergeHistograms(r.CorrectedHistograms, other.CorrectedHistograms)
+}
+
+// mergeFinalURLs adds up the redirected responses and keeps up to MaxFinalURLs
+// distinct final URLs.
+func (r *Result) mergeFinalURLs(other *Result) {
+	for url, finalURLs := range other.FinalURLs {
+		for _, finalURL := range sortedKeys(finalURLs) {
+			r.addFinalURL(url, finalURL, finalURLs[finalURL])
+		}
+	}
+}
+
+// mergeErrors adds up the errors and keeps up to MaxErrorSamples distinct
+// messages of each class.
+func (r *Result) mergeErrors(other *Result) {
+	for url, classes := range other.Errors {
+		if _, ok := r.Errors[url]; !ok {
+			r.Errors[url] = make(map[string]*ErrorResult)
+		}
+
+		for class, otherResult := range classes {
+			errorResult, ok := r.Errors[url][class]
+
+			if !ok {
+				errorResult = &ErrorResult{Samples: make([]string, 0)}
+				r.Errors[url][class] = errorResult
+			}
+
+			errorResult.Count += otherResult.Count
+
+			for _, sample := range otherResult.Samples {
+				if len(errorResult.Samples) >= MaxErrorSamples {
+					break
+				}
+
+				if !containsString(errorResult.Samples, sample) {
+					errorResult.Samples = append(errorResult.Samples, sample)
+				}
+			}
+		}
+	}
+}
+
+func (r *Result) mergePhaseTimes(other *Result) {
+	for url, phases := range other.PhaseTimes {
+		if _, ok := r.PhaseTimes[url]; !ok {
+			r.PhaseTimes[url] = &PhaseTimes{}
+		}
+
+		r.PhaseTimes[url].DNS += phases.DNS
+		r.PhaseTimes[url].Connect += phases.Connect
+		r.PhaseTimes[url].TLSHandshake += phases.TLSHandshake
+		r.PhaseTimes[url].TimeToFirstByte += phases.TimeToFirstByte
+		r.PhaseTimes[url].Download += phases.Download
+	}
+}
+
+// mergeStageResults adds up the results of the stages with the same name and
+// appends the others.
+func (r *Result) mergeStageResults(other *Result) {
+	for _, otherResult := range other.StageResult {
+		var stageResult *StageResult
+
+		for _, result := range r.StageResult {
+			if result.Name == otherResult.Name {
+				stageResult = result
+				break
+			}
+		}
+
+		if stageResult == nil {
+			stageResult = &StageResult{Name: otherResult.Name}
+			r.StageResult = append(r.StageResult, stageResult)
+		}
+
+		stageResult.TotalRequests += otherResult.TotalRequests
+		stageResult.SuccessfulRequests += otherResult.SuccessfulRequests
+		stageResult.FailedRequests += otherResult.FailedRequests
+		stageResult.TimedOutRequests += otherResult.TimedOutRequests
+		stageResult.TotalResponseTime += otherResult.TotalResponseTime
+		stageResult.ResponseTimesCount += otherResult.ResponseTimesCount
+	}
+}
+
+func (r *Result) mergeScenarioResults(other *Result) {
+	for scenario, otherResult := range other.ScenarioResult {
+		scenarioResult := r.getScenarioResult(scenario)
+
+		if len(scenarioResult.Steps) == 0 {
+			scenarioResult.Steps = otherResult.Steps
+		}
+
+		scenarioResult.Iterations += otherResult.Iterations
+		scenarioResult.SuccessfulIterations += otherResult.SuccessfulIterations
+		scenarioResult.FailedIterations += otherResult.FailedIterations
+		scenarioResult.TotalTime += otherResult.TotalTime
+		scenarioResult.ShortestTime = minDuration(scenarioResult.ShortestTime, otherResult.ShortestTime)
+
+		if otherResult.LongestTime > scenarioResult.LongestTime {
+			scenarioResult.LongestTime = otherResult.LongestTime
+		}
+
+		mergeIntMaps(scenarioResult.FailedSteps, otherResult.FailedSteps)
+		scenarioResult.TimeHistogram.Merge(otherResult.TimeHistogram)
+	}
+}
+
+func (r *Result) mergeTimeSeries(other *Result) {
+	for url, buckets := range other.TimeSeries {
+		for index, otherBucket := range buckets {
+			for len(r.TimeSeries[url]) <= index {
+				r.TimeSeries[url] = append(r.TimeSeries[url], &TimeBucket{
+					StatusClasses: make(map[string]int),
+					Histogram:     NewHistogram(),
+				})
+			}
+
+			bucket := r.TimeSeries[url][index]
+			bucket.SentRequests += otherBucket.SentRequests
+			bucket.FailedRequests += otherBucket.FailedRequests
+			bucket.TimedOutRequests += otherBucket.TimedOutRequests
+			bucket.ReceivedDataLength += otherBucket.ReceivedDataLength
+			mergeIntMaps(bucket.StatusClasses, otherBucket.StatusClasses)
+			bucket.Histogram.Merge(otherBucket.Histogram)
+		}
+	}
+}
+
+func (r *Result) mergeConcurrencyResults(other *Result) {
+	for url, results := range other.ConcurrencyResult {
+		if r.ConcurrencyWindow <= 0 {
+			for _, result := range results {
+				copied := *result
+				r.ConcurrencyResult[url] = append(r.ConcurrencyResult[url], &copied)
+			}
+
+			continue
+		}
+
+		for index, result := range results {
+			for len(r.ConcurrencyResult[url]) <= index {
+				r.ConcurrencyResult[url] = append(r.ConcurrencyResult[url], &ConcurrencyResult{})
+			}
+
+			r.ConcurrencyResult[url][index].TotalRequests += result.TotalRequests
+			r.ConcurrencyResult[url][index].SuccessfulRequests += result.SuccessfulRequests
+			r.ConcurrencyResult[url][index].FailedRequests += result.FailedRequests
+			r.ConcurrencyResult[url][index].TimedOutRequests += result.TimedOutRequests
+		}
+	}
+}
+
+func mergeIntMaps(m, other map[string]int) {
+	for key, count := range other {
+		m[key] += count
+	}
+}
+
+func mergeNestedIntMaps(m, other map[string]map[string]int) {
+	for url, counts := range other {
+		if _, ok := m[url]; !ok {
+			m[url] = make(map[string]int)
+		}
+
+		mergeIntMaps(m[url], counts)
+	}
+}
+
+func mergeStatusCodes(m, other map[string]map[int]int) {
+	for url, statusCodes := range other {
+		if _, ok := m[url]; !ok {
+			m[url] = make(map[int]int)
+		}
+
+		for statusCode, count := range statusCodes {
+			m[url][statusCode] += count
+		}
+	}
+}
+
+func mergeHistograms(m, other map[string]*Histogram) {
+	for url, histogram := range other {
+		if _, ok := m[url]; !ok {
+			m[url] = NewHistogram()
+		}
+
+		m[url].Merge(histogram)
+	}
+}
+
+// minDuration returns the shorter of two durations where zero means unknown.
+func minDuration(a, b time.Duration) time.Duration {
+	if a == 0 || (b != 0 && b < a) {
+		return b
+	}
+
+	return a
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+func sortedKeys(m map[string]int) []string {
+	keys := make([]string, 0, len(m))
+
+	for key := range m {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,339 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func mergeTestResults(t *testing.T, results ...*Result) *Result {
+	merged := &Result{}
+	merged.Init(0)
+
+	for _, result := range results {
+		if err := merged.Merge(result); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	return merged
+}
+
+func TestMergeCounters(t *testing.T) {
+	r1, r2 := getTestResultStruct(), getTestResultStruct()
+
+	r1.AddSentRequest("url1")
+	r1.AddResponseStatusCode("url1", 200, false)
+	r1.AddReceivedDataLength("url1", 100)
+	r1.AddResponseProtocol("url1", "HTTP/1.1")
+	r1.AddConnection("url1", false)
+	r1.AddRedirects("url1", 2, "final1")
+	r1.AddFailedAssertion("url1", "status")
+	r1.AddDroppedRequest("url1")
+
+	r2.AddSentRequest("url1")
+	r2.AddResponseStatusCode("url1", 200, false)
+	r2.AddResponseStatusCode("url1", 500, true)
+	r2.AddReceivedDataLength("url1", 50)
+	r2.AddResponseProtocol("url1", "HTTP/1.1")
+	r2.AddResponseProtocol("url1", "HTTP/2.0")
+	r2.AddConnection("url1", true)
+	r2.AddRedirects("url1", 1, "final2")
+	r2.AddFailedAssertion("url1", "status")
+	r2.AddSentRequest("url2")
+	r2.AddTimedoutResponse("url2")
+	r2.AddFailedResponse("url2")
+
+	r := mergeTestResults(t, r1, r2)
+
+	if len(r.URLs) != 2 || r.TotalRequests != 5 || r.SuccessfulRequests != 2 || r.FailedRequests != 2 || r.TimedOutRequests != 1 || r.DroppedRequests != 1 {
+		t.Errorf("Unexpected counters: %d %d %d %d %d", r.TotalRequests, r.SuccessfulRequests, r.FailedRequests, r.TimedOutRequests, r.DroppedRequests)
+	}
+
+	if r.TotalReceivedDataLength != 150 || r.ReceivedDataLength["url1"] != 150 {
+		t.Errorf("Unexpected received data length: %d %d", r.TotalReceivedDataLength, r.ReceivedDataLength["url1"])
+	}
+
+	if r.ResponseStatusCode["url1"][200] != 2 || r.FailedResponseStatusCode["url1"][500] != 1 {
+		t.Errorf("Unexpected status codes: %v %v", r.ResponseStatusCode, r.FailedResponseStatusCode)
+	}
+
+	if r.TimedoutResponse["url2"] != 1 || r.FailedResponse["url2"] != 1 || r.DroppedRequest["url1"] != 1 {
+		t.Error("Unexpected timed out, failed or dropped requests")
+	}
+
+	if !reflect.DeepEqual(r.Protocols["url1"], map[string]int{"HTTP/1.1": 2, "HTTP/2.0": 1}) {
+		t.Errorf("Unexpected protocols: %v", r.Protocols)
+	}
+
+	if r.NewConnections["url1"] != 1 || r.ReusedConnections["url1"] != 1 {
+		t.Errorf("Unexpected connections: %v %v", r.NewConnections, r.ReusedConnections)
+	}
+
+	if r.Redirects["url1"] != 3 || !reflect.DeepEqual(r.FinalURLs["url1"], map[string]int{"final1": 1, "final2": 1}) {
+		t.Errorf("Unexpected redirects: %v %v", r.Redirects, r.FinalURLs)
+	}
+
+	if r.FailedAssertions["url1"]["status"] != 2 {
+		t.Errorf("Unexpected failed assertions: %v", r.FailedAssertions)
+	}
+}
+
+func TestMergeTimes(t *testing.T) {
+	r1, r2 := getTestResultStruct(), getTestResultStruct()
+	start := time.Now()
+
+	r1.SetStartTime(start)
+	r1.SetEndTime(start.Add(2 * time.Second))
+	r1.SetTotalDuration(2 * time.Second)
+	r1.SetStopReason("requests")
+
+	r2.SetStartTime(start.Add(-time.Second))
+	r2.SetEndTime(start.Add(time.Second))
+	r2.SetTotalDuration(2 * time.Second)
+	r2.SetStopReason("duration")
+
+	r := mergeTestResults(t, r1, r2)
+
+	if !r.StartTime.Equal(start.Add(-time.Second)) || !r.EndTime.Equal(start.Add(2*time.Second)) {
+		t.Errorf("Unexpected start and end times: %s %s", r.StartTime, r.EndTime)
+	}
+
+	if r.TotalTime != 3*time.Second || r.StopReason != "requests" {
+		t.Errorf("Unexpected total time and stop reason: %s %s", r.TotalTime, r.StopReason)
+	}
+}
+
+func TestMergeResponseTimes(t *testing.T) {
+	r1, r2 := getTestResultStruct(), getTestResultStruct()
+	r2.SetExpectedInterval(10 * time.Millisecond)
+
+	r1.AddResponseTime("url1", 20*time.Millisecond)
+	r1.AddResponseTime("url1", 40*time.Millisecond)
+	r1.AddPhaseTimes("url1", PhaseTimes{DNS: time.Millisecond, Download: 2 * time.Millisecond})
+	r2.AddResponseTime("url1", 10*time.Millisecond)
+	r2.AddResponseTime("url2", 50*time.Millisecond)
+	r2.AddPhaseTimes("url1", PhaseTimes{DNS: time.Millisecond, Connect: 3 * time.Millisecond})
+
+	r := mergeTestResults(t, r1, r2)
+
+	if r.TotalResponseTime != 120*time.Millisecond || r.ResponseTimesTotalCount != 4 {
+		t.Errorf("Unexpected total response time: %s %d", r.TotalResponseTime, r.ResponseTimesTotalCount)
+	}
+
+	if r.ShortestResponseTime != 10*time.Millisecond || r.LongestResponseTime != 50*time.Millisecond {
+		t.Errorf("Unexpected shortest and longest response times: %s %s", r.ShortestResponseTime, r.LongestResponseTime)
+	}
+
+	if r.ResponseTime["url1"] != 70*time.Millisecond || r.ResponseTimesCount["url1"] != 3 {
+		t.Errorf("Unexpected response time of url1: %s %d", r.ResponseTime["url1"], r.ResponseTimesCount["url1"])
+	}
+
+	if r.ShortestResponseTimes["url1"] != 10*time.Millisecond || r.LongestResponseTimes["url1"] != 40*time.Millisecond || r.ShortestResponseTimes["url2"] != 50*time.Millisecond {
+		t.Errorf("Unexpected shortest and longest response times by URL: %v %v", r.ShortestResponseTimes, r.LongestResponseTimes)
+	}
+
+	if r.Histogram.TotalCount != 4 || r.Histograms["url1"].TotalCount != 3 || r.Histogram.Min != 10*time.Millisecond || r.Histogram.Max != 50*time.Millisecond {
+		t.Errorf("Unexpected histograms: %+v %+v", r.Histogram, r.Histograms["url1"])
+	}
+
+	if r.ExpectedInterval != 10*time.Millisecond || r.CorrectedHistogram.TotalCount != r2.CorrectedHistogram.TotalCount || r.CorrectedHistograms["url2"].TotalCount != 5 {
+		t.Errorf("Unexpected corrected histograms: %+v", r.CorrectedHistogram)
+	}
+
+	expected := PhaseTimes{DNS: 2 * time.Millisecond, Connect: 3 * time.Millisecond, Download: 2 * time.Millisecond}
+
+	if *r.PhaseTimes["url1"] != expected || r.PhaseTimesCount["url1"] != 2 {
+		t.Errorf("Expected phase times %+v but got %+v", expected, r.PhaseTimes["url1"])
+	}
+}
+
+func TestMergeErrors(t *testing.T) {
+	r1, r2 := getTestResultStruct(), getTestResultStruct()
+
+	r1.AddError("url1", ErrorDNS, "first")
+	r1.AddError("url1", ErrorDNS, "second")
+	r2.AddError("url1", ErrorDNS, "second")
+	r2.AddError("url1", ErrorDNS, "third")
+	r2.AddError("url1", ErrorDNS, "fourth")
+	r2.AddError("url2", ErrorEOF, "eof")
+
+	r := mergeTestResults(t, r1, r2)
+
+	dnsErrors := r.Errors["url1"][ErrorDNS]
+
+	if dnsErrors.Count != 5 || !reflect.DeepEqual(dnsErrors.Samples, []string{"first", "second", "third"}) {
+		t.Errorf("Unexpected DNS errors: %+v", dnsErrors)
+	}
+
+	if r.Errors["url2"][ErrorEOF].Count != 1 {
+		t.Errorf("Unexpected EOF errors: %+v", r.Errors["url2"])
+	}
+}
+
+func TestMergeFinalURLs(t *testing.T) {
+	r1, r2 := getTestResultStruct(), getTestResultStruct()
+
+	for i := 0; i < MaxFinalURLs; i++ {
+		r1.AddRedirects("url1", 1, fmt.Sprintf("final%02d", i))
+		r2.AddRedirects("url1", 1, fmt.Sprintf("final%02d", i+2))
+	}
+
+	r2.AddRedirects("url1", 1, "final99")
+
+	r := mergeTestResults(t, r1, r2)
+	finalURLs := r.FinalURLs["url1"]
+
+	if len(finalURLs) != MaxFinalURLs+1 || finalURLs["final02"] != 2 || finalURLs[OtherFinalURLs] != 3 {
+		t.Errorf("Unexpected final URLs: %v", finalURLs)
+	}
+}
+
+func TestMergeStagesAndScenarios(t *testing.T) {
+	r1, r2 := getTestResultStruct(), getTestResultStruct()
+
+	r1.AddStageResponse("ramp", 10*time.Millisecond, false, false)
+	r2.AddStageResponse("ramp", 20*time.Millisecond, true, false)
+	r2.AddStageResponse("steady", 0, false, true)
+
+	r1.AddScenario("login", []string{"home", "login"})
+	r1.AddScenarioResult("login", 30*time.Millisecond, "")
+	r2.AddScenario("login", []string{"home", "login"})
+	r2.AddScenarioResult("login", 10*time.Millisecond, "login")
+	r2.AddScenarioResult("login", 50*time.Millisecond, "")
+
+	r := mergeTestResults(t, r1, r2)
+
+	expectedStages := []*StageResult{
+		{Name: "ramp", TotalRequests: 2, SuccessfulRequests: 1, FailedRequests: 1, TotalResponseTime: 30 * time.Millisecond, ResponseTimesCount: 2},
+		{Name: "steady", TotalRequests: 1, TimedOutRequests: 1},
+	}
+
+	if !reflect.DeepEqual(r.StageResult, expectedStages) {
+		t.Errorf("Expected stages %+v but got %+v", expectedStages, r.StageResult)
+	}
+
+	scenario := r.ScenarioResult["login"]
+
+	if !reflect.DeepEqual(scenario.Steps, []string{"home", "login"}) || scenario.Iterations != 3 || scenario.SuccessfulIterations != 2 || scenario.FailedIterations != 1 {
+		t.Errorf("Unexpected scenario iterations: %+v", scenario)
+	}
+
+	if scenario.FailedSteps["login"] != 1 || scenario.TotalTime != 90*time.Millisecond || scenario.TimeHistogram.TotalCount != 3 {
+		t.Errorf("Unexpected scenario result: %+v", scenario)
+	}
+
+	if scenario.ShortestTime != 10*time.Millisecond || scenario.LongestTime != 50*time.Millisecond {
+		t.Errorf("Unexpected scenario times: %s %s", scenario.ShortestTime, scenario.LongestTime)
+	}
+}
+
+func TestMergeTimeSeries(t *testing.T) {
+	start := time.Now()
+	now := start
+	results := make([]*Result, 0, 2)
+
+	for i := 0; i < 2; i++ {
+		r := getTestResultStruct()
+		r.now = func() time.Time {
+			return now
+		}
+		r.SetStartTime(start)
+		r.SetTimeSeriesInterval(time.Second)
+		results = append(results, r)
+	}
+
+	now = start
+	results[0].AddSentRequest("url1")
+	results[0].AddResponseStatusCode("url1", 200, false)
+	results[1].AddSentRequest("url1")
+	results[1].AddResponseTime("url1", time.Millisecond)
+
+	now = start.Add(1500 * time.Millisecond)
+	results[1].AddSentRequest("url1")
+	results[1].AddTimedoutResponse("url1")
+
+	r := mergeTestResults(t, results...)
+
+	if r.TimeSeriesInterval != time.Second || len(r.TimeSeries["url1"]) != 2 {
+		t.Fatalf("Unexpected time series: %s %v", r.TimeSeriesInterval, r.TimeSeries)
+	}
+
+	first, second := r.TimeSeries["url1"][0], r.TimeSeries["url1"][1]
+
+	if first.SentRequests != 2 || first.StatusClasses["2xx"] != 1 || first.Histogram.TotalCount != 1 {
+		t.Errorf("Unexpected first bucket: %+v", first)
+	}
+
+	if second.SentRequests != 1 || second.TimedOutRequests != 1 {
+		t.Errorf("Unexpected second bucket: %+v", second)
+	}
+
+	other := getTestResultStruct()
+	other.SetStartTime(start)
+	other.SetTimeSeriesInterval(time.Minute)
+	other.AddSentRequest("url1")
+	expected := "Time series intervals do not match: 1s and 1m0s"
+
+	if err := r.Merge(other); err == nil || err.Error() != expected {
+		t.Errorf("Expected %q but got %v", expected, err)
+	}
+}
+
+func TestMergeConcurrency(t *testing.T) {
+	r1, r2 := getTestResultStruct(), getTestResultStruct()
+
+	r1.AddResponseStatusCode("url1", 200, false)
+	r1.AddResponseStatusCode("url1", 200, false)
+	r2.AddResponseStatusCode("url1", 200, true)
+
+	r := mergeTestResults(t, r1, r2)
+
+	expected := []*ConcurrencyResult{
+		{TotalRequests: 2, SuccessfulRequests: 2},
+		{TotalRequests: 1, FailedRequests: 1},
+	}
+
+	if !reflect.DeepEqual(r.ConcurrencyResult["url1"], expected) {
+		t.Errorf("Expected batches %+v but got %+v", expected, r.ConcurrencyResult["url1"])
+	}
+
+	w1, w2 := getTestResultStruct(), getTestResultStruct()
+	w1.SetStartTime(time.Now())
+	w2.SetStartTime(time.Now())
+	w1.SetConcurrencyWindow(time.Hour)
+	w2.SetConcurrencyWindow(time.Hour)
+	w1.AddResponseStatusCode("url1", 200, false)
+	w2.AddTimedoutResponse("url1")
+
+	r = mergeTestResults(t, w1, w2)
+
+	expected = []*ConcurrencyResult{{TotalRequests: 2, SuccessfulRequests: 1, TimedOutRequests: 1}}
+
+	if r.ConcurrencyWindow != time.Hour || !reflect.DeepEqual(r.ConcurrencyResult["url1"], expected) {
+		t.Errorf("Expected windows %+v but got %+v", expected, r.ConcurrencyResult["url1"])
+	}
+
+	expectedErr := "Concurrency windows do not match: 1h0m0s and 0s"
+
+	if err := r.Merge(r1); err == nil || err.Error() != expectedErr {
+		t.Errorf("Expected %q but got %v", expectedErr, err)
+	}
+}
+
+func TestMergeDecodedResult(t *testing.T) {
+	decoded := &Result{}
+	encoded := `{"urls": {"url1": true}, "total-requests": 1, "successful-requests": 1, "response-status-code": {"url1": {"200": 1}}}`
+
+	if err := json.Unmarshal([]byte(encoded), decoded); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	r := mergeTestResults(t, decoded, decoded)
+
+	if r.TotalRequests != 2 || r.ResponseStatusCode["url1"][200] != 2 || r.Histogram.TotalCount != 0 {
+		t.Errorf("Unexpected merged result: %+v", r)
+	}
+}
@@ -3,16 +3,77 @@ package report
 
 import "time"
 
+// Stop reasons describe the condition which ended a benchmark.
+const (
+	// StopReasonRequests means all the requests are sent.
+	StopReasonRequests = "requests"
+	// StopReasonDuration means the benchmark duration is elapsed.
+	StopReasonDuration = "duration"
+	// StopReasonStages means all the load stages are finished.
+	StopReasonStages = "stages"
+	// StopReasonCanceled means the benchmark is canceled (i.e. by a signal).
+	StopReasonCanceled = "canceled"
+	// StopReasonFeeder means all the rows of a once feeder are used.
+	StopReasonFeeder = "feeder"
+)
+
+// Error classes group the errors of the requests by their cause.
+const (
+	// ErrorDNS means the host could not be resolved.
+	ErrorDNS = "dns"
+	// ErrorConnectionRefused means the server refused the connection.
+	ErrorConnectionRefused = "connection-refused"
+	// ErrorConnectionReset means the connection was reset by the server.
+	ErrorConnectionReset = "connection-reset"
+	// ErrorTLS means the TLS handshake or the certificate verification failed.
+	ErrorTLS = "tls"
+	// ErrorEOF means the connection was closed before a response.
+	ErrorEOF = "eof"
+	// ErrorCanceled means the request was canceled (i.e. by the end of the
+	// benchmark).
+	ErrorCanceled = "canceled"
+	// ErrorBodyRead means the body of a response could not be read.
+	ErrorBodyRead = "body-read"
+	// ErrorOther is any other error.
+	ErrorOther = "other"
+)
+
+// MaxErrorSamples is the number of distinct messages kept for each error class
+// of a URL.
+const MaxErrorSamples = 3
+
+// MaxFinalURLs is the number of distinct final URLs kept for the redirected
+// responses of a URL. The responses from any other final URL are counted under
+// OtherFinalURLs.
+const MaxFinalURLs = 10
+
+// OtherFinalURLs is the key of the redirected responses whose final URL is not
+// kept.
+const OtherFinalURLs = "other"
+
 // Report defines the interface for a type report that can be used with
 // benchmarks to store the result.
 type Report interface {
+	AddSentRequest(url string)
 	AddReceivedDataLength(url string, contentLength int64)
 	SetTotalDuration(duration time.Duration)
 	AddResponseTime(url string, time time.Duration)
+	AddPhaseTimes(url string, phases PhaseTimes)
 	AddResponseStatusCode(url string, statusCode int, failed bool)
 	AddTimedoutResponse(url string)
 	AddFailedResponse(url string)
+	AddError(url, class, message string)
+	AddFailedAssertion(url, assertion string)
+	AddResponseProtocol(url, protocol string)
+	AddConnection(url string, reused bool)
+	AddRedirects(url string, redirects int, finalURL string)
+	AddDroppedRequest(url string)
+	AddStageResponse(stage string, responseTime time.Duration, failed, timedOut bool)
+	AddScenario(scenario string, steps []string)
+	AddScenarioResult(scenario string, duration time.Duration, failedStep string)
 	Init(concurrency int)
 	SetStartTime(t time.Time)
 	SetEndTime(t time.Time)
+	SetStopReason(reason string)
+	SetExpectedInterval(interval time.Duration)
 }
@@ -16,18 +16,44 @@ func (r *Result) Init(concurrency int) {
 	r.FailedResponseStatusCode = make(map[string]map[int]int)
 	r.TimedoutResponse = make(map[string]int)
 	r.FailedResponse = make(map[string]int)
+	r.DroppedRequest = make(map[string]int)
+	r.FailedAssertions = make(map[string]map[string]int)
+	r.Protocols = make(map[string]map[string]int)
+	r.Redirects = make(map[string]int)
+	r.Errors = make(map[string]map[string]*ErrorResult)
+	r.FinalURLs = make(map[string]map[string]int)
 	r.ShortestResponseTimes = make(map[string]time.Duration)
 	r.LongestResponseTimes = make(map[string]time.Duration)
 	r.concurrency = concurrency
 	r.URLs = make(map[string]bool)
 	r.ResponseTimesCount = make(map[string]int)
+	r.PhaseTimes = make(map[string]*PhaseTimes)
+	r.Histogram = NewHistogram()
+	r.Histograms = make(map[string]*Histogram)
+	r.PhaseTimesCount = make(map[string]int)
+	r.ReusedConnections = make(map[string]int)
+	r.NewConnections = make(map[string]int)
+
+	r.StageResult = make([]*StageResult, 0)
+	r.ScenarioResult = make(map[string]*ScenarioResult)
+	r.TimeSeries = make(map[string][]*TimeBucket)
 
 	r.ConcurrencyResult = make(map[string][]*ConcurrencyResult)
 	r.concurrencyCounter = make(map[string]int)
 
+	r.now = time.Now
 	r.lock = &sync.Mutex{}
 }
 
+// SetConcurrencyWindow groups the concurrency results into fixed time windows
+// since the start time instead of batches of concurrent requests.
+func (r *Result) SetConcurrencyWindow(window time.Duration) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.ConcurrencyWindow = window
+}
+
 // SetStartTime sets benchmark's start time.
 func (r *Result) SetStartTime(t time.Time) {
 	r.lock.Lock()
@@ -44,6 +70,28 @@ func (r *Result) SetEndTime(t time.Time) {
 	r.EndTime = t
 }
 
+// SetStopReason sets the condition which ended the benchmark.
+func (r *Result) SetStopReason(reason string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.StopReason = reason
+}
+
+// SetExpectedInterval sets the expected interval between requests of a single
+// worker and enables the response times corrected for coordinated omission.
+func (r *Result) SetExpectedInterval(interval time.Duration) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.ExpectedInterval = interval
+
+	if interval > 0 {
+		r.CorrectedHistogram = NewHistogram()
+		r.CorrectedHistograms = make(map[string]*Histogram)
+	}
+}
+
 // AddReceivedDataLength adds content length received to the Total
 // amount for a specific URL.
 func (r *Result) AddReceivedDataLength(url string, contentLength int64) {
@@ -57,6 +105,10 @@ func (r *Result) AddReceivedDataLength(url string, contentLength int64) {
 		return
 	}
 
+	if bucket := r.getTimeBucket(url); bucket != nil {
+		bucket.ReceivedDataLength += contentLength
+	}
+
 	r.TotalReceivedDataLength += contentLength
 
 	if _, ok := r.ReceivedDataLength[url]; ok {
@@ -95,6 +147,26 @@ func (r *Result) AddResponseTime(url string, responseTime time.Duration) {
 	r.updateURLShortestTime(url, responseTime)
 	r.updateURLLongestTime(url, responseTime)
 
+	if _, ok := r.Histograms[url]; !ok {
+		r.Histograms[url] = NewHistogram()
+	}
+
+	r.Histogram.Record(responseTime)
+	r.Histograms[url].Record(responseTime)
+
+	if bucket := r.getTimeBucket(url); bucket != nil {
+		bucket.Histogram.Record(responseTime)
+	}
+
+	if r.ExpectedInterval > 0 {
+		if _, ok := r.CorrectedHistograms[url]; !ok {
+			r.CorrectedHistograms[url] = NewHistogram()
+		}
+
+		r.CorrectedHistogram.RecordCorrected(responseTime, r.ExpectedInterval)
+		r.CorrectedHistograms[url].RecordCorrected(responseTime, r.ExpectedInterval)
+	}
+
 	if _, ok := r.ResponseTime[url]; ok {
 		r.ResponseTime[url] += responseTime
 		r.ResponseTimesCount[url]++
@@ -105,6 +177,26 @@ func (r *Result) AddResponseTime(url string, responseTime time.Duration) {
 	r.ResponseTime[url] = responseTime
 }
 
+// AddPhaseTimes adds the time spent in each phase of a request to the total
+// amount for a specific URL.
+func (r *Result) AddPhaseTimes(url string, phases PhaseTimes) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.URLs[url] = true
+	r.PhaseTimesCount[url]++
+
+	if _, ok := r.PhaseTimes[url]; !ok {
+		r.PhaseTimes[url] = &PhaseTimes{}
+	}
+
+	r.PhaseTimes[url].DNS += phases.DNS
+	r.PhaseTimes[url].Connect += phases.Connect
+	r.PhaseTimes[url].TLSHandshake += phases.TLSHandshake
+	r.PhaseTimes[url].TimeToFirstByte += phases.TimeToFirstByte
+	r.PhaseTimes[url].Download += phases.Download
+}
+
 func (r *Result) updateURLShortestTime(url string, time time.Duration) {
 	if _, ok := r.ShortestResponseTimes[url]; !ok {
 		r.ShortestResponseTimes[url] = time
@@ -137,6 +229,10 @@ func (r *Result) AddResponseStatusCode(url string, statusCode int, failed bool)
 
 	r.TotalRequests++
 
+	if bucket := r.getTimeBucket(url); bucket != nil {
+		bucket.StatusClasses[statusClass(statusCode)]++
+	}
+
 	if failed {
 		r.updateConcurrencyResult(url, 0, 1, 0)
 		r.FailedRequests++
@@ -171,6 +267,10 @@ func (r *Result) AddTimedoutResponse(url string) {
 
 	r.updateConcurrencyResult(url, 0, 0, 1)
 
+	if bucket := r.getTimeBucket(url); bucket != nil {
+		bucket.TimedOutRequests++
+	}
+
 	r.TimedOutRequests++
 	r.TotalRequests++
 
@@ -191,6 +291,10 @@ func (r *Result) AddFailedResponse(url string) {
 
 	r.updateConcurrencyResult(url, 0, 1, 0)
 
+	if bucket := r.getTimeBucket(url); bucket != nil {
+		bucket.FailedRequests++
+	}
+
 	r.FailedRequests++
 	r.TotalRequests++
 
@@ -202,7 +306,221 @@ func (r *Result) AddFailedResponse(url string) {
 	r.FailedResponse[url] = 1
 }
 
+// AddError adds an error of a class for a url and keeps its message when less
+// than MaxErrorSamples distinct messages are kept for the class. The failed
+// request itself is added with AddFailedResponse or AddResponseStatusCode.
+func (r *Result) AddError(url, class, message string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if _, ok := r.Errors[url]; !ok {
+		r.Errors[url] = make(map[string]*ErrorResult)
+	}
+
+	errorResult, ok := r.Errors[url][class]
+
+	if !ok {
+		errorResult = &ErrorResult{Samples: make([]string, 0)}
+		r.Errors[url][class] = errorResult
+	}
+
+	errorResult.Count++
+
+	if len(errorResult.Samples) >= MaxErrorSamples {
+		return
+	}
+
+	for _, sample := range errorResult.Samples {
+		if sample == message {
+			return
+		}
+	}
+
+	errorResult.Samples = append(errorResult.Samples, message)
+}
+
+// AddFailedAssertion increases the number of failures of an assertion for a
+// specific URL. The failed response itself is added with AddResponseStatusCode.
+func (r *Result) AddFailedAssertion(url, assertion string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if _, ok := r.FailedAssertions[url]; !ok {
+		r.FailedAssertions[url] = make(map[string]int)
+	}
+
+	r.FailedAssertions[url][assertion]++
+}
+
+// AddResponseProtocol increases the number of responses of a specific URL
+// received over a protocol.
+func (r *Result) AddResponseProtocol(url, protocol string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if _, ok := r.Protocols[url]; !ok {
+		r.Protocols[url] = make(map[string]int)
+	}
+
+	r.Protocols[url][protocol]++
+}
+
+// AddRedirects adds the number of redirects followed by a request of a
+// specific URL and the final URL its response was received from. No more than
+// MaxFinalURLs distinct final URLs are kept for a URL.
+func (r *Result) AddRedirects(url string, redirects int, finalURL string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.Redirects[url] += redirects
+	r.addFinalURL(url, finalURL, 1)
+}
+
+// addFinalURL adds responses received from a final URL and counts them under
+// OtherFinalURLs once MaxFinalURLs distinct final URLs are kept.
+func (r *Result) addFinalURL(url, finalURL string, count int) {
+	finalURLs, ok := r.FinalURLs[url]
+
+	if !ok {
+		finalURLs = make(map[string]int)
+		r.FinalURLs[url] = finalURLs
+	}
+
+	if _, ok := finalURLs[finalURL]; !ok {
+		kept := len(finalURLs)
+
+		if _, ok := finalURLs[OtherFinalURLs]; ok {
+			kept--
+		}
+
+		if kept >= MaxFinalURLs {
+			finalURL = OtherFinalURLs
+		}
+	}
+
+	finalURLs[finalURL] += count
+}
+
+// AddConnection increases the number of requests of a specific URL which
+// reused a connection or opened a new one.
+func (r *Result) AddConnection(url string, reused bool) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if reused {
+		r.ReusedConnections[url]++
+		return
+	}
+
+	r.NewConnections[url]++
+}
+
+// AddDroppedRequest increaments the number of requests for a url which are
+// not sent because too many requests were in flight. Dropped requests are not
+// counted as sent requests.
+func (r *Result) AddDroppedRequest(url string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.URLs[url] = true
+
+	r.DroppedRequests++
+	r.DroppedRequest[url]++
+}
+
+// AddStageResponse adds the result of a request to the load stage in which it
+// was sent. Response time is only taken into account when it is not zero.
+func (r *Result) AddStageResponse(stage string, responseTime time.Duration, failed, timedOut bool) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	var stageResult *StageResult
+
+	for _, result := range r.StageResult {
+		if result.Name == stage {
+			stageResult = result
+			break
+		}
+	}
+
+	if stageResult == nil {
+		stageResult = &StageResult{Name: stage}
+		r.StageResult = append(r.StageResult, stageResult)
+	}
+
+	stageResult.TotalRequests++
+
+	switch {
+	case timedOut:
+		stageResult.TimedOutRequests++
+	case failed:
+		stageResult.FailedRequests++
+	default:
+		stageResult.SuccessfulRequests++
+	}
+
+	if responseTime > 0 {
+		stageResult.TotalResponseTime += responseTime
+		stageResult.ResponseTimesCount++
+	}
+}
+
+// AddScenario adds a scenario with the names of its steps in order.
+func (r *Result) AddScenario(scenario string, steps []string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.getScenarioResult(scenario).Steps = steps
+}
+
+// AddScenarioResult adds the result of an iteration of a scenario. An empty
+// failed step means all the steps of the iteration succeeded.
+func (r *Result) AddScenarioResult(scenario string, duration time.Duration, failedStep string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	scenarioResult := r.getScenarioResult(scenario)
+	scenarioResult.Iterations++
+
+	if failedStep != "" {
+		scenarioResult.FailedIterations++
+		scenarioResult.FailedSteps[failedStep]++
+	} else {
+		scenarioResult.SuccessfulIterations++
+	}
+
+	scenarioResult.TotalTime += duration
+	scenarioResult.TimeHistogram.Record(duration)
+
+	if scenarioResult.ShortestTime == 0 || scenarioResult.ShortestTime > duration {
+		scenarioResult.ShortestTime = duration
+	}
+
+	if scenarioResult.LongestTime < duration {
+		scenarioResult.LongestTime = duration
+	}
+}
+
+func (r *Result) getScenarioResult(scenario string) *ScenarioResult {
+	scenarioResult, ok := r.ScenarioResult[scenario]
+
+	if !ok {
+		scenarioResult = &ScenarioResult{
+			FailedSteps:   make(map[string]int),
+			TimeHistogram: NewHistogram(),
+		}
+		r.ScenarioResult[scenario] = scenarioResult
+	}
+
+	return scenarioResult
+}
+
 func (r *Result) updateConcurrencyResult(url string, successfulRequests, failedRequests, timedOutRequests int) {
+	if r.ConcurrencyWindow > 0 {
+		r.updateConcurrencyWindow(url, successfulRequests, failedRequests, timedOutRequests)
+		return
+	}
+
 	if r.concurrency == 0 {
 		return
 	}
@@ -233,3 +551,16 @@ func (r *Result) updateConcurrencyResult(url string, successfulRequests, failedR
 	r.ConcurrencyResult[url][lenResult-1].SuccessfulRequests += successfulRequests
 	r.ConcurrencyResult[url][lenResult-1].TimedOutRequests += timedOutRequests
 }
+
+func (r *Result) updateConcurrencyWindow(url string, successfulRequests, failedRequests, timedOutRequests int) {
+	index := r.timeIndex(r.ConcurrencyWindow)
+
+	for len(r.ConcurrencyResult[url]) <= index {
+		r.ConcurrencyResult[url] = append(r.ConcurrencyResult[url], &ConcurrencyResult{})
+	}
+
+	r.ConcurrencyResult[url][index].TotalRequests++
+	r.ConcurrencyResult[url][index].FailedRequests += failedRequests
+	r.ConcurrencyResult[url][index].SuccessfulRequests += successfulRequests
+	r.ConcurrencyResult[url][index].TimedOutRequests += timedOutRequests
+}
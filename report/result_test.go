@@ -1,6 +1,9 @@
 package report
 
 import (
+	"encoding/json"
+	"fmt"
+	"strings"
 	"testing"
 	"time"
 )
@@ -29,6 +32,16 @@ func TestTimes(t *testing.T) {
 	}
 }
 
+func TestStopReason(t *testing.T) {
+	r := getTestResultStruct()
+
+	r.SetStopReason(StopReasonDuration)
+
+	if r.StopReason != StopReasonDuration {
+		t.Errorf("Expected %q as stop reason but got %q", StopReasonDuration, r.StopReason)
+	}
+}
+
 func TestContentLength(t *testing.T) {
 	r := getTestResultStruct()
 
@@ -122,6 +135,60 @@ func TestResponseTime(t *testing.T) {
 	if r.ResponseTimesCount["testURL2"] != 1 {
 		t.Errorf("Expected to get 1 for ResponseTimesCount for testURL2 but got %d", r.ResponseTimesCount["testURL1"])
 	}
+
+	if r.Histogram.TotalCount != 3 || r.Histograms["testURL1"].TotalCount != 2 || r.Histograms["testURL2"].TotalCount != 1 {
+		t.Error("Unexpected number of values in histograms")
+	}
+
+	// Percentiles are within 1% of the recorded values.
+	if p50 := r.Histogram.Percentile(50); p50 < 10*time.Second || p50 > 10100*time.Millisecond ||
+		r.Histograms["testURL1"].Percentile(100) != 10*time.Second {
+		t.Errorf("Unexpected percentiles: %v %v", r.Histogram.Percentile(50), r.Histograms["testURL1"].Percentile(100))
+	}
+}
+
+func TestPhaseTimes(t *testing.T) {
+	r := getTestResultStruct()
+
+	r.AddPhaseTimes("testURL1", PhaseTimes{DNS: 1, Connect: 2, TLSHandshake: 3, TimeToFirstByte: 4, Download: 5})
+	r.AddPhaseTimes("testURL1", PhaseTimes{TimeToFirstByte: 6, Download: 7})
+	r.AddPhaseTimes("testURL2", PhaseTimes{DNS: 1})
+
+	expected := PhaseTimes{DNS: 1, Connect: 2, TLSHandshake: 3, TimeToFirstByte: 10, Download: 12}
+
+	if *r.PhaseTimes["testURL1"] != expected || r.PhaseTimesCount["testURL1"] != 2 {
+		t.Errorf("Expected %+v for testURL1 but got %+v", expected, r.PhaseTimes["testURL1"])
+	}
+
+	if r.PhaseTimes["testURL2"].DNS != 1 || r.PhaseTimesCount["testURL2"] != 1 {
+		t.Errorf("Unexpected phase times for testURL2: %+v", r.PhaseTimes["testURL2"])
+	}
+
+	checkURLs(t, r, []string{"testURL1", "testURL2"})
+}
+
+func TestCorrectedResponseTime(t *testing.T) {
+	r := getTestResultStruct()
+
+	r.AddResponseTime("testURL1", 3*time.Second)
+
+	if r.CorrectedHistogram != nil || len(r.CorrectedHistograms) != 0 {
+		t.Fatal("Did not expect corrected histograms without an expected interval")
+	}
+
+	r.SetExpectedInterval(time.Second)
+	r.AddResponseTime("testURL1", 3*time.Second)
+	r.AddResponseTime("testURL2", 500*time.Millisecond)
+
+	if r.Histogram.TotalCount != 3 {
+		t.Errorf("Expected 3 raw response times but got %d", r.Histogram.TotalCount)
+	}
+
+	if r.CorrectedHistogram.TotalCount != 4 ||
+		r.CorrectedHistograms["testURL1"].TotalCount != 3 ||
+		r.CorrectedHistograms["testURL2"].TotalCount != 1 {
+		t.Error("Unexpected number of corrected response times")
+	}
 }
 
 func TestTotalTime(t *testing.T) {
@@ -204,6 +271,187 @@ func TestFailedResponse(t *testing.T) {
 	checkURLs(t, r, []string{"testURL1", "testURL2"})
 }
 
+func TestFailedAssertion(t *testing.T) {
+	r := getTestResultStruct()
+
+	r.AddFailedAssertion("testURL1", "body-contains:ok")
+	r.AddFailedAssertion("testURL1", "body-contains:ok")
+	r.AddFailedAssertion("testURL1", "max-latency:200ms")
+	r.AddFailedAssertion("testURL2", "body-contains:ok")
+
+	if r.FailedAssertions["testURL1"]["body-contains:ok"] != 2 || r.FailedAssertions["testURL1"]["max-latency:200ms"] != 1 {
+		t.Errorf("Unexpected failed assertions for testURL1: %v", r.FailedAssertions["testURL1"])
+	}
+
+	if len(r.FailedAssertions["testURL2"]) != 1 || r.FailedAssertions["testURL2"]["body-contains:ok"] != 1 {
+		t.Errorf("Unexpected failed assertions for testURL2: %v", r.FailedAssertions["testURL2"])
+	}
+}
+
+func TestError(t *testing.T) {
+	r := getTestResultStruct()
+
+	r.AddError("testURL1", ErrorConnectionRefused, "connection refused 1")
+	r.AddError("testURL1", ErrorConnectionRefused, "connection refused 1")
+
+	for i := 2; i <= MaxErrorSamples+2; i++ {
+		r.AddError("testURL1", ErrorConnectionRefused, fmt.Sprintf("connection refused %d", i))
+	}
+
+	r.AddError("testURL1", ErrorEOF, "EOF")
+
+	refused := r.Errors["testURL1"][ErrorConnectionRefused]
+
+	if refused == nil || refused.Count != MaxErrorSamples+3 || len(refused.Samples) != MaxErrorSamples {
+		t.Fatalf("Unexpected connection refused errors: %+v", refused)
+	}
+
+	if refused.Samples[0] != "connection refused 1" || refused.Samples[1] != "connection refused 2" {
+		t.Errorf("Unexpected samples: %v", refused.Samples)
+	}
+
+	if eof := r.Errors["testURL1"][ErrorEOF]; eof == nil || eof.Count != 1 || len(eof.Samples) != 1 {
+		t.Errorf("Unexpected EOF errors: %+v", eof)
+	}
+}
+
+func TestResponseProtocol(t *testing.T) {
+	r := getTestResultStruct()
+
+	r.AddResponseProtocol("testURL1", "HTTP/2.0")
+	r.AddResponseProtocol("testURL1", "HTTP/2.0")
+	r.AddResponseProtocol("testURL1", "HTTP/1.1")
+
+	if len(r.Protocols["testURL1"]) != 2 || r.Protocols["testURL1"]["HTTP/2.0"] != 2 || r.Protocols["testURL1"]["HTTP/1.1"] != 1 {
+		t.Errorf("Unexpected protocols for testURL1: %v", r.Protocols["testURL1"])
+	}
+}
+
+func TestRedirects(t *testing.T) {
+	r := getTestResultStruct()
+
+	r.AddRedirects("testURL1", 2, "http://final.com/a")
+	r.AddRedirects("testURL1", 1, "http://final.com/b")
+	r.AddRedirects("testURL1", 2, "http://final.com/a")
+
+	if r.Redirects["testURL1"] != 5 {
+		t.Errorf("Expected 5 redirects but got %d", r.Redirects["testURL1"])
+	}
+
+	if finalURLs := r.FinalURLs["testURL1"]; len(finalURLs) != 2 || finalURLs["http://final.com/a"] != 2 || finalURLs["http://final.com/b"] != 1 {
+		t.Errorf("Unexpected final URLs: %v", finalURLs)
+	}
+
+	for i := 0; i < MaxFinalURLs+5; i++ {
+		r.AddRedirects("testURL2", 1, fmt.Sprintf("http://final.com/%d", i))
+	}
+
+	r.AddRedirects("testURL2", 1, "http://final.com/0")
+
+	if finalURLs := r.FinalURLs["testURL2"]; len(finalURLs) != MaxFinalURLs+1 || finalURLs[OtherFinalURLs] != 5 || finalURLs["http://final.com/0"] != 2 {
+		t.Errorf("Unexpected capped final URLs: %v", finalURLs)
+	}
+}
+
+func TestConnection(t *testing.T) {
+	r := getTestResultStruct()
+
+	r.AddConnection("testURL1", false)
+	r.AddConnection("testURL1", true)
+	r.AddConnection("testURL1", true)
+	r.AddConnection("testURL2", false)
+
+	if r.NewConnections["testURL1"] != 1 || r.ReusedConnections["testURL1"] != 2 {
+		t.Errorf("Unexpected connections for testURL1: %d new and %d reused", r.NewConnections["testURL1"], r.ReusedConnections["testURL1"])
+	}
+
+	if r.NewConnections["testURL2"] != 1 || r.ReusedConnections["testURL2"] != 0 {
+		t.Errorf("Unexpected connections for testURL2: %d new and %d reused", r.NewConnections["testURL2"], r.ReusedConnections["testURL2"])
+	}
+}
+
+func TestDroppedRequest(t *testing.T) {
+	r := getTestResultStruct()
+
+	r.AddDroppedRequest("testURL1")
+	r.AddDroppedRequest("testURL1")
+	r.AddDroppedRequest("testURL2")
+
+	if r.DroppedRequest["testURL1"] != 2 || r.DroppedRequest["testURL2"] != 1 {
+		t.Errorf("Unexpected dropped requests: %v", r.DroppedRequest)
+	}
+
+	if r.DroppedRequests != 3 || r.TotalRequests != 0 {
+		t.Error("Dropped requests should not be counted as sent requests")
+	}
+
+	checkURLs(t, r, []string{"testURL1", "testURL2"})
+}
+
+func TestStageResponse(t *testing.T) {
+	r := getTestResultStruct()
+
+	r.AddStageResponse("ramp", 2*time.Second, false, false)
+	r.AddStageResponse("ramp", 4*time.Second, true, false)
+	r.AddStageResponse("hold", 0, false, true)
+	r.AddStageResponse("ramp", 0, true, false)
+
+	if len(r.StageResult) != 2 || r.StageResult[0].Name != "ramp" || r.StageResult[1].Name != "hold" {
+		t.Fatalf("Unexpected stage results: %+v", r.StageResult)
+	}
+
+	ramp, hold := r.StageResult[0], r.StageResult[1]
+
+	if ramp.TotalRequests != 3 || ramp.SuccessfulRequests != 1 || ramp.FailedRequests != 2 || ramp.TimedOutRequests != 0 {
+		t.Errorf("Unexpected counts for ramp: %+v", ramp)
+	}
+
+	if ramp.TotalResponseTime != 6*time.Second || ramp.ResponseTimesCount != 2 {
+		t.Errorf("Unexpected response times for ramp: %+v", ramp)
+	}
+
+	if hold.TotalRequests != 1 || hold.TimedOutRequests != 1 || hold.ResponseTimesCount != 0 {
+		t.Errorf("Unexpected result for hold: %+v", hold)
+	}
+
+	encoded, _ := json.Marshal(hold)
+
+	if !strings.Contains(string(encoded), `"total-requests":1`) {
+		t.Errorf("Unexpected encoded stage result: %s", encoded)
+	}
+}
+
+func TestScenarioResult(t *testing.T) {
+	r := getTestResultStruct()
+
+	r.AddScenario("checkout", []string{"checkout > login", "checkout > pay"})
+	r.AddScenarioResult("checkout", 2*time.Second, "")
+	r.AddScenarioResult("checkout", time.Second, "checkout > pay")
+	r.AddScenarioResult("checkout", 3*time.Second, "checkout > pay")
+
+	result, ok := r.ScenarioResult["checkout"]
+
+	if !ok || len(result.Steps) != 2 || result.Steps[1] != "checkout > pay" {
+		t.Fatalf("Unexpected scenario result: %+v", r.ScenarioResult)
+	}
+
+	if result.Iterations != 3 || result.SuccessfulIterations != 1 || result.FailedIterations != 2 {
+		t.Errorf("Unexpected iterations: %+v", result)
+	}
+
+	if result.FailedSteps["checkout > pay"] != 2 || len(result.FailedSteps) != 1 {
+		t.Errorf("Unexpected failed steps: %v", result.FailedSteps)
+	}
+
+	if result.TotalTime != 6*time.Second || result.ShortestTime != time.Second || result.LongestTime != 3*time.Second {
+		t.Errorf("Unexpected times: %+v", result)
+	}
+
+	if result.TimeHistogram.TotalCount != 3 {
+		t.Errorf("Expected 3 recorded times but got %d", result.TimeHistogram.TotalCount)
+	}
+}
+
 func TestStatusCode(t *testing.T) {
 	r := getTestResultStruct()
 
@@ -265,6 +513,43 @@ func TestStatusCode(t *testing.T) {
 	checkURLs(t, r, []string{"testURL1", "testURL2", "testURL3"})
 }
 
+func TestConcurrencyWindow(t *testing.T) {
+	r := getTestResultStruct()
+
+	start := time.Now()
+	now := start
+
+	r.now = func() time.Time {
+		return now
+	}
+
+	r.SetStartTime(start)
+	r.SetConcurrencyWindow(time.Second)
+
+	if r.ConcurrencyWindow != time.Second {
+		t.Fatalf("Expected one second as concurrency window but got %v", r.ConcurrencyWindow)
+	}
+
+	r.AddResponseStatusCode("testURL1", 200, false)
+	r.AddResponseStatusCode("testURL1", 500, true)
+	r.AddResponseStatusCode("testURL1", 200, false)
+
+	now = start.Add(2500 * time.Millisecond)
+
+	r.AddTimedoutResponse("testURL1")
+	r.AddFailedResponse("testURL2")
+
+	if len(r.ConcurrencyResult["testURL1"]) != 3 || len(r.ConcurrencyResult["testURL2"]) != 3 {
+		t.Fatalf("Expected 3 windows but got %d and %d", len(r.ConcurrencyResult["testURL1"]), len(r.ConcurrencyResult["testURL2"]))
+	}
+
+	checkConcurrencyResult(t, r.ConcurrencyResult["testURL1"][0], 3, 2, 1, 0)
+	checkConcurrencyResult(t, r.ConcurrencyResult["testURL1"][1], 0, 0, 0, 0)
+	checkConcurrencyResult(t, r.ConcurrencyResult["testURL1"][2], 1, 0, 0, 1)
+	checkConcurrencyResult(t, r.ConcurrencyResult["testURL2"][0], 0, 0, 0, 0)
+	checkConcurrencyResult(t, r.ConcurrencyResult["testURL2"][2], 1, 0, 1, 0)
+}
+
 func checkConcurrencyResult(t *testing.T,
 	result *ConcurrencyResult,
 	TotalRequests,
@@ -17,14 +17,27 @@ type Result struct {
 	FailedResponseStatusCode map[string]map[int]int `json:"failed-response-status-code"`
 	TimedoutResponse         map[string]int         `json:"timedout-response"`
 	FailedResponse           map[string]int         `json:"failed-response"`
-	TotalRequests            int                    `json:"total-requests"`
-	SuccessfulRequests       int                    `json:"successful-requests"`
-	FailedRequests           int                    `json:"failed-requests"`
-	TimedOutRequests         int                    `json:"timedout-requests"`
+	DroppedRequest           map[string]int         `json:"dropped-request"`
+	// Number of responses by URL and negotiated protocol (i.e. HTTP/2.0).
+	Protocols map[string]map[string]int `json:"protocols"`
+	// Number of followed redirects by URL and number of the redirected
+	// responses by URL and the final URL they were received from.
+	Redirects map[string]int            `json:"redirects"`
+	FinalURLs map[string]map[string]int `json:"final-urls"`
+	// Errors of the requests by URL and error class.
+	Errors map[string]map[string]*ErrorResult `json:"errors"`
+	// Number of failed assertions by URL and assertion.
+	FailedAssertions   map[string]map[string]int `json:"failed-assertions"`
+	TotalRequests      int                       `json:"total-requests"`
+	SuccessfulRequests int                       `json:"successful-requests"`
+	FailedRequests     int                       `json:"failed-requests"`
+	TimedOutRequests   int                       `json:"timedout-requests"`
+	DroppedRequests    int                       `json:"dropped-requests"`
 
 	StartTime               time.Time                `json:"start-time"`
 	EndTime                 time.Time                `json:"end-time"`
 	TotalTime               time.Duration            `json:"total-time"`
+	StopReason              string                   `json:"stop-reason"`
 	TotalResponseTime       time.Duration            `json:"total-response-time"`
 	ResponseTimesTotalCount int                      `json:"response-times-total-count"`
 	ResponseTime            map[string]time.Duration `json:"response-time"`
@@ -34,18 +47,99 @@ type Result struct {
 	ShortestResponseTime    time.Duration            `json:"shortest-response-time"`
 	LongestResponseTime     time.Duration            `json:"longest-response-time"`
 
+	// Histograms of response times for all and each of the URLs.
+	Histogram  *Histogram            `json:"histogram"`
+	Histograms map[string]*Histogram `json:"histograms"`
+
+	// Histograms of response times corrected for coordinated omission. They
+	// are only recorded when an expected interval is set.
+	ExpectedInterval    time.Duration         `json:"expected-interval"`
+	CorrectedHistogram  *Histogram            `json:"corrected-histogram"`
+	CorrectedHistograms map[string]*Histogram `json:"corrected-histograms"`
+
+	PhaseTimes      map[string]*PhaseTimes `json:"phase-times"`
+	PhaseTimesCount map[string]int         `json:"phase-times-count"`
+
+	// Number of requests of each URL which reused an idle connection or
+	// opened a new one.
+	ReusedConnections map[string]int `json:"reused-connections"`
+	NewConnections    map[string]int `json:"new-connections"`
+
+	StageResult []*StageResult `json:"stage-result"`
+
+	ScenarioResult map[string]*ScenarioResult `json:"scenario-result"`
+
+	TimeSeriesInterval time.Duration            `json:"time-series-interval"`
+	TimeSeries         map[string][]*TimeBucket `json:"time-series"`
+
 	ConcurrencyResult  map[string][]*ConcurrencyResult `json:"concurrency-result"`
+	ConcurrencyWindow  time.Duration                   `json:"concurrency-window"`
 	concurrencyCounter map[string]int
 	concurrency        int
 
+	now func() time.Time
+
 	lock *sync.Mutex
 }
 
 // ConcurrencyResult struct store the result for each batch of concurrent
-// requests.
+// requests. When a concurrency window is set, a batch is the responses
+// received during a time window instead.
 type ConcurrencyResult struct {
 	TotalRequests      int `json:"total-request"`
 	SuccessfulRequests int `json:"successful-requests"`
 	FailedRequests     int `json:"failed-requests"`
 	TimedOutRequests   int `json:"timedout-requests"`
 }
+
+// ErrorResult struct stores the number of errors of a class and a few of
+// their distinct messages.
+type ErrorResult struct {
+	Count   int      `json:"count"`
+	Samples []string `json:"samples"`
+}
+
+// PhaseTimes struct stores the time spent in each phase of a request. In the
+// result, it stores the sum of the phase times of all the requests to a URL.
+type PhaseTimes struct {
+	// DNS lookup.
+	DNS time.Duration `json:"dns"`
+	// Establishing the TCP connection.
+	Connect time.Duration `json:"connect"`
+	// TLS handshake.
+	TLSHandshake time.Duration `json:"tls-handshake"`
+	// Time between writing the request and receiving the first byte of the
+	// response.
+	TimeToFirstByte time.Duration `json:"time-to-first-byte"`
+	// Time between receiving the first byte and reading the whole body.
+	Download time.Duration `json:"download"`
+}
+
+// StageResult struct stores the result of the requests sent during a load
+// stage.
+type StageResult struct {
+	Name               string        `json:"name"`
+	TotalRequests      int           `json:"total-requests"`
+	SuccessfulRequests int           `json:"successful-requests"`
+	FailedRequests     int           `json:"failed-requests"`
+	TimedOutRequests   int           `json:"timedout-requests"`
+	TotalResponseTime  time.Duration `json:"total-response-time"`
+	ResponseTimesCount int           `json:"response-times-count"`
+}
+
+// ScenarioResult struct stores the result of the iterations of a scenario. The
+// requests of each step are stored as the requests of a URL whose name is one
+// of the steps.
+type ScenarioResult struct {
+	// Names of the steps in order.
+	Steps                []string `json:"steps"`
+	Iterations           int      `json:"iterations"`
+	SuccessfulIterations int      `json:"successful-iterations"`
+	FailedIterations     int      `json:"failed-iterations"`
+	// Number of failed iterations by the step which they stopped at.
+	FailedSteps   map[string]int `json:"failed-steps"`
+	TotalTime     time.Duration  `json:"total-time"`
+	ShortestTime  time.Duration  `json:"shortest-time"`
+	LongestTime   time.Duration  `json:"longest-time"`
+	TimeHistogram *Histogram     `json:"time-histogram"`
+}
@@ -0,0 +1,70 @@
+package report
+
+import "time"
+
+// Summary contains the totals of a whole benchmark or of one of its URLs.
+type Summary struct {
+	Requests, Successful, Failed, TimedOut, Dropped int
+	// Duration of the whole benchmark.
+	TotalTime time.Duration
+	// Sum and number of the response times.
+	ResponseTime  time.Duration
+	ResponseTimes int
+	ShortestTime  time.Duration
+	LongestTime   time.Duration
+	Histogram     *Histogram
+	// Length of the received data in bytes.
+	ReceivedDataLength int64
+}
+
+// Summary returns the summary of the whole benchmark.
+func (r *Result) Summary() *Summary {
+	return &Summary{
+		Requests:           r.TotalRequests,
+		Successful:         r.SuccessfulRequests,
+		Failed:             r.FailedRequests,
+		TimedOut:           r.TimedOutRequests,
+		Dropped:            r.DroppedRequests,
+		TotalTime:          r.TotalTime,
+		ResponseTime:       r.TotalResponseTime,
+		ResponseTimes:      r.ResponseTimesTotalCount,
+		ShortestTime:       r.ShortestResponseTime,
+		LongestTime:        r.LongestResponseTime,
+		Histogram:          r.Histogram,
+		ReceivedDataLength: r.TotalReceivedDataLength,
+	}
+}
+
+// URLSummary returns the summary of a URL. Failed requests include the
+// responses with a failed status code. It returns nil when the URL is not
+// benchmarked in the result.
+func (r *Result) URLSummary(url string) *Summary {
+	if !r.URLs[url] {
+		return nil
+	}
+
+	s := &Summary{
+		Failed:             r.FailedResponse[url],
+		TimedOut:           r.TimedoutResponse[url],
+		Dropped:            r.DroppedRequest[url],
+		TotalTime:          r.TotalTime,
+		ResponseTime:       r.ResponseTime[url],
+		ResponseTimes:      r.ResponseTimesCount[url],
+		ShortestTime:       r.ShortestResponseTimes[url],
+		LongestTime:        r.LongestResponseTimes[url],
+		Histogram:          r.Histograms[url],
+		ReceivedDataLength: r.ReceivedDataLength[url],
+	}
+
+	for _, count := range r.ResponseStatusCode[url] {
+		s.Successful += count
+	}
+
+	for _, count := range r.FailedResponseStatusCode[url] {
+		s.Failed += count
+	}
+
+	s.Requests = s.Successful + s.Failed + s.TimedOut
+
+	return s
+}
@@ -0,0 +1,41 @@
+package report
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSummary(t *testing.T) {
+	r := getTestResultStruct()
+
+	r.AddResponseStatusCode("url1", 200, false)
+	r.AddResponseStatusCode("url1", 500, true)
+	r.AddResponseTime("url1", 10*time.Millisecond)
+	r.AddResponseTime("url1", 30*time.Millisecond)
+	r.AddTimedoutResponse("url2")
+	r.AddFailedResponse("url2")
+	r.AddDroppedRequest("url2")
+	r.AddReceivedDataLength("url1", 100)
+
+	s := r.URLSummary("url1")
+
+	if s.Requests != 2 || s.Successful != 1 || s.Failed != 1 || s.ResponseTime != 40*time.Millisecond || s.ResponseTimes != 2 || s.ReceivedDataLength != 100 {
+		t.Errorf("Unexpected summary of url1: %+v", s)
+	}
+
+	if s.ShortestTime != 10*time.Millisecond || s.LongestTime != 30*time.Millisecond || s.Histogram.TotalCount != 2 {
+		t.Errorf("Unexpected response times of url1: %+v", s)
+	}
+
+	if s := r.URLSummary("url2"); s.Requests != 2 || s.Failed != 1 || s.TimedOut != 1 || s.Dropped != 1 {
+		t.Errorf("Unexpected summary of url2: %+v", s)
+	}
+
+	if r.URLSummary("url3") != nil {
+		t.Error("Expected no summary for a URL which is not benchmarked")
+	}
+
+	if s := r.Summary(); s.Requests != r.TotalRequests || s.Successful != r.SuccessfulRequests || s.Histogram != r.Histogram {
+		t.Errorf("Unexpected summary: %+v", s)
+	}
+}
@@ -0,0 +1,262 @@
+package report
+
+import (
+	"fmt"
+	"math"
+	neturl "net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Kinds of the values of the threshold metrics.
+const (
+	durationMetric = iota
+	percentMetric
+	throughputMetric
+	countMetric
+)
+
+// thresholdMetrics maps the metrics which can be used in thresholds to the
+// kind of their values. Latency percentiles (i.e. p99_latency) are accepted as
+// well.
+var thresholdMetrics = map[string]int{
+	"avg_latency":  durationMetric,
+	"min_latency":  durationMetric,
+	"max_latency":  durationMetric,
+	"success_rate": percentMetric,
+	"failure_rate": percentMetric,
+	"timeout_rate": percentMetric,
+	"throughput":   throughputMetric,
+	"requests":     countMetric,
+	"successes":    countMetric,
+	"failures":     countMetric,
+	"timeouts":     countMetric,
+	"dropped":      countMetric,
+}
+
+var (
+	thresholdRegexp  = regexp.MustCompile(`^\s*([a-z0-9_.]+)\s*(?:\{\s*url\s*=\s*([^}]*?)\s*\})?\s*(<=|>=|==|!=|<|>)\s*(\S+)\s*$`)
+	percentileRegexp = regexp.MustCompile(`^p(\d+(?:\.\d+)?)_latency$`)
+)
+
+// Threshold is a condition which the result of a benchmark should meet (i.e.
+// avg_latency{url=/api} < 200ms, success_rate > 99.5% or timeouts == 0).
+type Threshold struct {
+	// Expression of the threshold as it is given.
+	Expression string
+	// Metric, optional URL filter, comparison operator and value of the
+	// threshold. Durations are stored in nanoseconds.
+	Metric, URL, Operator string
+	Value                 float64
+
+	kind       int
+	percentile float64
+}
+
+// NewThreshold parses a threshold expression in the format of
+// metric[{url=filter}] operator value. The URL filter matches either a whole
+// URL or its path.
+func NewThreshold(expression string) (*Threshold, error) {
+	match := thresholdRegexp.FindStringSubmatch(expression)
+
+	if match == nil {
+		return nil, fmt.Errorf("Wrong threshold format: %s (The format should be metric[{url=path}] operator value)", expression)
+	}
+
+	t := &Threshold{Expression: strings.TrimSpace(expression), Metric: match[1], URL: match[2], Operator: match[3]}
+
+	kind, ok := thresholdMetrics[t.Metric]
+
+	if percentile := percentileRegexp.FindStringSubmatch(t.Metric); percentile != nil {
+		t.percentile, _ = strconv.ParseFloat(percentile[1], 64)
+		kind, ok = durationMetric, t.percentile > 0 && t.percentile <= 100
+	}
+
+	if !ok {
+		return nil, fmt.Errorf("Wrong threshold metric: %s", t.Metric)
+	}
+
+	t.kind = kind
+
+	value, err := parseThresholdValue(kind, match[4])
+
+	if err != nil {
+		return nil, fmt.Errorf("Wrong threshold value of %s: %s", t.Metric, match[4])
+	}
+
+	t.Value = value
+
+	return t, nil
+}
+
+func parseThresholdValue(kind int, value string) (float64, error) {
+	switch kind {
+	case durationMetric:
+		d, err := time.ParseDuration(value)
+		return float64(d), err
+	case percentMetric:
+		return strconv.ParseFloat(strings.TrimSuffix(value, "%"), 64)
+	case throughputMetric:
+		return strconv.ParseFloat(strings.TrimSuffix(value, "/s"), 64)
+	}
+
+	return strconv.ParseFloat(value, 64)
+}
+
+// ThresholdResult is the outcome of checking a threshold against the result
+// of a benchmark.
+type ThresholdResult struct {
+	Threshold *Threshold
+	// URL which the value belongs to. It is empty for the whole benchmark.
+	URL string
+	// Value of the metric formatted like the threshold (i.e. 150ms or 99.5%).
+	Value  string
+	Passed bool
+	// Reason of a failure without a value (i.e. no matching URL).
+	Err error
+}
+
+// Check checks the threshold against a result. A threshold with a URL filter
+// is checked for each of the matching URLs.
+func (t *Threshold) Check(r *Result) []*ThresholdResult {
+	if t.URL == "" {
+		return []*ThresholdResult{t.check(r.Summary(), "")}
+	}
+
+	urls := r.matchURLs(t.URL)
+
+	if len(urls) == 0 {
+		return []*ThresholdResult{{Threshold: t, Err: fmt.Errorf("No URL matches %s", t.URL)}}
+	}
+
+	results := make([]*ThresholdResult, 0, len(urls))
+
+	for _, url := range urls {
+		results = append(results, t.check(r.URLSummary(url), url))
+	}
+
+	return results
+}
+
+func (t *Threshold) check(s *Summary, url string) *ThresholdResult {
+	result := &ThresholdResult{Threshold: t, URL: url}
+	value := t.value(s)
+
+	if math.IsNaN(value) {
+		result.Err = fmt.Errorf("No value for %s", t.Metric)
+		return result
+	}
+
+	result.Value = t.format(value)
+
+	switch t.Operator {
+	case "<":
+		result.Passed = value < t.Value
+	case "<=":
+		result.Passed = value <= t.Value
+	case ">":
+		result.Passed = value > t.Value
+	case ">=":
+		result.Passed = value >= t.Value
+	case "==":
+		result.Passed = value == t.Value
+	case "!=":
+		result.Passed = value != t.Value
+	}
+
+	return result
+}
+
+func (t *Threshold) value(s *Summary) float64 {
+	if t.percentile > 0 {
+		if s.Histogram == nil || s.Histogram.TotalCount == 0 {
+			return math.NaN()
+		}
+
+		return float64(s.Histogram.Percentile(t.percentile))
+	}
+
+	switch t.Metric {
+	case "avg_latency":
+		if s.ResponseTimes == 0 {
+			return math.NaN()
+		}
+
+		return float64(s.ResponseTime.Nanoseconds() / int64(s.ResponseTimes))
+	case "min_latency":
+		return float64(s.ShortestTime)
+	case "max_latency":
+		return float64(s.LongestTime)
+	case "success_rate":
+		return rate(s.Successful, s.Requests)
+	case "failure_rate":
+		return rate(s.Failed, s.Requests)
+	case "timeout_rate":
+		return rate(s.TimedOut, s.Requests)
+	case "throughput":
+		if s.TotalTime <= 0 {
+			return math.NaN()
+		}
+
+		return float64(s.Requests) / s.TotalTime.Seconds()
+	case "requests":
+		return float64(s.Requests)
+	case "successes":
+		return float64(s.Successful)
+	case "failures":
+		return float64(s.Failed)
+	case "timeouts":
+		return float64(s.TimedOut)
+	case "dropped":
+		return float64(s.Dropped)
+	}
+
+	return math.NaN()
+}
+
+func (t *Threshold) format(value float64) string {
+	switch t.kind {
+	case durationMetric:
+		return time.Duration(value).String()
+	case percentMetric:
+		return fmt.Sprintf("%.2f%%", value)
+	case throughputMetric:
+		return fmt.Sprintf("%.2f/s", value)
+	}
+
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+func rate(n, total int) float64 {
+	if total == 0 {
+		return math.NaN()
+	}
+
+	return float64(n*100) / float64(total)
+}
+
+// matchURLs returns the URLs which are either equal to the filter or whose
+// path (with the query) is equal to the filter.
+func (r *Result) matchURLs(filter string) []string {
+	urls := make([]string, 0)
+
+	for url := range r.URLs {
+		if url == filter {
+			urls = append(urls, url)
+			continue
+		}
+
+		u, err := neturl.Parse(url)
+
+		if err == nil && (u.Path == filter || u.RequestURI() == filter || u.Path == "" && filter == "/") {
+			urls = append(urls, url)
+		}
+	}
+
+	sort.Strings(urls)
+
+	return urls
+}
@@ -0,0 +1,116 @@
+package report
+
+import (
+	"testing"
+	"time"
+)
+
+func getThresholdTestResult() *Result {
+	r := getTestResultStruct()
+
+	for i := 0; i < 10; i++ {
+		r.AddSentRequest("http://host.com/api")
+		r.AddResponseTime("http://host.com/api", 100*time.Millisecond)
+		r.AddResponseStatusCode("http://host.com/api", 200, false)
+
+		r.AddSentRequest("http://host.com/slow?page=1")
+		r.AddResponseTime("http://host.com/slow?page=1", 300*time.Millisecond)
+		r.AddResponseStatusCode("http://host.com/slow?page=1", 200, i == 0)
+	}
+
+	r.AddSentRequest("http://host.com/api")
+	r.AddTimedoutResponse("http://host.com/api")
+	r.SetTotalDuration(2 * time.Second)
+
+	return r
+}
+
+func TestThreshold(t *testing.T) {
+	r := getThresholdTestResult()
+
+	tests := map[string]bool{
+		"avg_latency < 250ms":                     true,
+		"avg_latency{url=/api} < 200ms":           true,
+		"avg_latency{url=/slow} < 200ms":          false,
+		"avg_latency{url=/slow?page=1} <= 300ms":  true,
+		"avg_latency{url=http://host.com/api}<1s": true,
+		"max_latency >= 300ms":                    true,
+		"min_latency > 100ms":                     false,
+		"p99_latency{url=/api} < 110ms":           true,
+		"p99.9_latency < 200ms":                   false,
+		"success_rate > 99.5%":                    false,
+		"success_rate{url=/slow} == 90%":          true,
+		"failure_rate < 5":                        true,
+		"timeout_rate{url=/api} > 0%":             true,
+		"timeouts == 0":                           false,
+		"timeouts{url=/slow} == 0":                true,
+		"requests == 21":                          true,
+		"successes != 20":                         true,
+		"failures <= 1":                           true,
+		"dropped == 0":                            true,
+		"throughput >= 10/s":                      true,
+		"throughput > 11":                         false,
+	}
+
+	for expression, expected := range tests {
+		threshold, err := NewThreshold(expression)
+
+		if err != nil {
+			t.Fatalf("Unexpected error for %s: %v", expression, err)
+		}
+
+		results := threshold.Check(r)
+
+		if len(results) != 1 || results[0].Err != nil || results[0].Passed != expected {
+			t.Errorf("Expected %v for %s but got %+v", expected, expression, results[0])
+		}
+	}
+}
+
+func TestThresholdResult(t *testing.T) {
+	r := getThresholdTestResult()
+	r.AddSentRequest("http://other.com/api")
+	r.AddResponseTime("http://other.com/api", time.Second)
+	r.AddResponseStatusCode("http://other.com/api", 200, false)
+
+	threshold, _ := NewThreshold("avg_latency{url=/api} < 200ms")
+	results := threshold.Check(r)
+
+	if len(results) != 2 || results[0].URL != "http://host.com/api" || !results[0].Passed || results[0].Value != "100ms" {
+		t.Fatalf("Unexpected result for the first URL: %+v", results[0])
+	}
+
+	if results[1].URL != "http://other.com/api" || results[1].Passed || results[1].Value != "1s" {
+		t.Errorf("Unexpected result for the second URL: %+v", results[1])
+	}
+
+	threshold, _ = NewThreshold("success_rate{url=/missing} > 99%")
+
+	if results := threshold.Check(r); len(results) != 1 || results[0].Passed || results[0].Err == nil || results[0].Err.Error() != "No URL matches /missing" {
+		t.Errorf("Unexpected result for a missing URL: %+v", results[0])
+	}
+
+	threshold, _ = NewThreshold("avg_latency < 1s")
+
+	if results := threshold.Check(getTestResultStruct()); results[0].Passed || results[0].Err == nil {
+		t.Errorf("Expected a threshold without a value to fail but got %+v", results[0])
+	}
+}
+
+func TestWrongThreshold(t *testing.T) {
+	wrongThresholds := map[string]string{
+		"avg_latency":               "Wrong threshold format: avg_latency (The format should be metric[{url=path}] operator value)",
+		"avg_latency => 1s":         "Wrong threshold format: avg_latency => 1s (The format should be metric[{url=path}] operator value)",
+		"latency < 1s":              "Wrong threshold metric: latency",
+		"p101_latency < 1s":         "Wrong threshold metric: p101_latency",
+		"avg_latency < 200":         "Wrong threshold value of avg_latency: 200",
+		"success_rate > high":       "Wrong threshold value of success_rate: high",
+		"avg_latency{path=/a} < 1s": "Wrong threshold format: avg_latency{path=/a} < 1s (The format should be metric[{url=path}] operator value)",
+	}
+
+	for expression, expected := range wrongThresholds {
+		if _, err := NewThreshold(expression); err == nil || err.Error() != expected {
+			t.Errorf("Expected %q but got %v", expected, err)
+		}
+	}
+}
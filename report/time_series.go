@@ -0,0 +1,73 @@
+package report
+
+import (
+	"fmt"
+	"time"
+)
+
+// TimeBucket struct stores the result of a URL during a fixed time interval
+// of the benchmark.
+type TimeBucket struct {
+	SentRequests       int            `json:"sent-requests"`
+	StatusClasses      map[string]int `json:"status-classes"`
+	FailedRequests     int            `json:"failed-requests"`
+	TimedOutRequests   int            `json:"timedout-requests"`
+	ReceivedDataLength int64          `json:"received-data-length"`
+	Histogram          *Histogram     `json:"histogram"`
+}
+
+// SetTimeSeriesInterval enables the time series of the result. Each bucket of
+// the time series covers the given interval since the start time.
+func (r *Result) SetTimeSeriesInterval(interval time.Duration) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.TimeSeriesInterval = interval
+}
+
+// AddSentRequest increaments the number of requests sent for a url.
+func (r *Result) AddSentRequest(url string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.URLs[url] = true
+
+	if bucket := r.getTimeBucket(url); bucket != nil {
+		bucket.SentRequests++
+	}
+}
+
+// getTimeBucket returns the current bucket of the time series of a url. It
+// returns nil when the time series is not enabled. Caller should hold the
+// lock.
+func (r *Result) getTimeBucket(url string) *TimeBucket {
+	if r.TimeSeriesInterval <= 0 {
+		return nil
+	}
+
+	index := r.timeIndex(r.TimeSeriesInterval)
+
+	for len(r.TimeSeries[url]) <= index {
+		r.TimeSeries[url] = append(r.TimeSeries[url], &TimeBucket{
+			StatusClasses: make(map[string]int),
+			Histogram:     NewHistogram(),
+		})
+	}
+
+	return r.TimeSeries[url][index]
+}
+
+// timeIndex returns the index of the current interval since the start time.
+func (r *Result) timeIndex(interval time.Duration) int {
+	index := int(r.now().Sub(r.StartTime) / interval)
+
+	if index < 0 {
+		return 0
+	}
+
+	return index
+}
+
+func statusClass(statusCode int) string {
+	return fmt.Sprintf("%dxx", statusCode/100)
+}
@@ -0,0 +1,66 @@
+package report
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTimeSeries(t *testing.T) {
+	r := getTestResultStruct()
+
+	start := time.Now()
+	now := start
+
+	r.now = func() time.Time {
+		return now
+	}
+
+	r.SetStartTime(start)
+	r.AddSentRequest("testURL1")
+
+	if len(r.TimeSeries) != 0 {
+		t.Fatal("Did not expect a time series without an interval")
+	}
+
+	r.SetTimeSeriesInterval(time.Second)
+
+	r.AddSentRequest("testURL1")
+	r.AddSentRequest("testURL1")
+	r.AddResponseTime("testURL1", 10*time.Millisecond)
+	r.AddReceivedDataLength("testURL1", 100)
+	r.AddResponseStatusCode("testURL1", 200, false)
+
+	now = start.Add(2 * time.Second)
+
+	r.AddResponseStatusCode("testURL1", 503, true)
+	r.AddTimedoutResponse("testURL1")
+	r.AddFailedResponse("testURL2")
+
+	if len(r.TimeSeries["testURL1"]) != 3 || len(r.TimeSeries["testURL2"]) != 3 {
+		t.Fatalf("Expected 3 buckets but got %d and %d", len(r.TimeSeries["testURL1"]), len(r.TimeSeries["testURL2"]))
+	}
+
+	first, last := r.TimeSeries["testURL1"][0], r.TimeSeries["testURL1"][2]
+
+	if first.SentRequests != 2 || first.StatusClasses["2xx"] != 1 || first.ReceivedDataLength != 100 || first.Histogram.TotalCount != 1 {
+		t.Errorf("Unexpected first bucket: %+v", first)
+	}
+
+	if last.SentRequests != 0 || last.StatusClasses["5xx"] != 1 || last.TimedOutRequests != 1 || last.FailedRequests != 0 {
+		t.Errorf("Unexpected last bucket: %+v", last)
+	}
+
+	if r.TimeSeries["testURL2"][2].FailedRequests != 1 || r.TimeSeries["testURL2"][0].Histogram.TotalCount != 0 {
+		t.Errorf("Unexpected buckets for testURL2: %+v", r.TimeSeries["testURL2"])
+	}
+}
+
+func TestStatusClass(t *testing.T) {
+	expected := map[int]string{101: "1xx", 200: "2xx", 204: "2xx", 302: "3xx", 404: "4xx", 599: "5xx"}
+
+	for statusCode, class := range expected {
+		if statusClass(statusCode) != class {
+			t.Errorf("Expected %s for %d but got %s", class, statusCode, statusClass(statusCode))
+		}
+	}
+}